@@ -23,14 +23,14 @@ import (
   "os"
   "fmt"
   . "github.com/QAhell/Parser-Gombinators/parse"
-  . "strconv"
 )
 
 /*
   Primary school arithmetic, left-associative, only binary operators
 
-  Digit        := "0" | .. | "9"
-  Number       := Digit Digit*
+  Number       := an integer literal with an optional sign, radix prefix
+                  and underscores, like -42, 0x_7f, 0b1010 or 1_000_000
+                  (see ExpectInt64)
   Multiplicand := Number
                 | "(" Expression ")"
   Adddend      := Multiplicand (("*" | "/") Multiplicand)*
@@ -42,9 +42,8 @@ import (
  */
 
 func Multiplicand (input ParserInput) ParserResult {
-  return ExpectNumber.Convert(atoi).OrElse (
-      expect ("(").AndThen (Expression).AndThen (expect (")")).
-        First().Second()) (input)
+  return MaybeSpacesBefore (ExpectInt64).OrElse (
+      Between (expect ("("), expect (")"), Expression)) (input)
 }
 
 func Addend (input ParserInput) ParserResult {
@@ -81,17 +80,13 @@ func main () {
     fmt.Printf (licence_notice)
   } else {
     var input = StringToInput (os.Args[1])
-    var parserResult = Expression (input)
-    var result, isInteger = parserResult.Result.(int)
+    var parserResult = Parser (Expression).AndThen (ExpectSpaces).First ().
+      ParseAll (input)
+    var result, isInteger = parserResult.Result.(int64)
     if isInteger {
       fmt.Printf ("result = %d\n", result)
-      if nil != parserResult.RemainingInput {
-        var inp = parserResult.RemainingInput.(RuneArrayInput)
-        var rest = inp.Text[inp.CurrentPosition:]
-        fmt.Printf ("There's some remaining input: %s\n", string (rest))
-      }
     } else {
-      fmt.Printf ("Couldn't read the input!\n")
+      fmt.Printf ("Couldn't read the input: %s\n", parserResult.Error)
     }
   }
 }
@@ -102,19 +97,14 @@ func expect (text string) Parser {
 
 func multiply (lhs interface{}, rhs interface{}) interface{} {
   if "*" == GetFirst (rhs).(string) {
-    return lhs.(int) * GetSecond (rhs).(int)
+    return lhs.(int64) * GetSecond (rhs).(int64)
   }
-  return lhs.(int) / GetSecond (rhs).(int)
+  return lhs.(int64) / GetSecond (rhs).(int64)
 }
 
 func add (lhs interface{}, rhs interface{}) interface{} {
   if "+" == GetFirst (rhs).(string) {
-    return lhs.(int) + GetSecond (rhs).(int)
+    return lhs.(int64) + GetSecond (rhs).(int64)
   }
-  return lhs.(int) - GetSecond (rhs).(int)
-}
-
-func atoi (arg interface{}) interface{} {
-  var result, _ = Atoi(arg.(string))
-  return result
+  return lhs.(int64) - GetSecond (rhs).(int64)
 }
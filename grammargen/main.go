@@ -0,0 +1,311 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+  "flag"
+  "fmt"
+  "go/token"
+  "go/types"
+  "os"
+  "path/filepath"
+  "sort"
+  "strconv"
+  "strings"
+  . "github.com/QAhell/Parser-Gombinators/parse"
+)
+
+/*
+  grammargen reads a grammar in EBNF (see parse.Grammar) and writes Go source
+  code that builds its parsers from the combinators of the parse package.
+  Every rule becomes a function with the name of the rule, so the generated
+  parsers read like hand-written ones and show up in stack traces. Use it with
+  go generate:
+
+    //go:generate go run github.com/QAhell/Parser-Gombinators/grammargen -actions Number,Expression calculator.ebnf
+
+  The results of the rules listed in -actions are converted by functions
+  named like convertNumber that you write next to the generated code. The
+  results are the same as those of parse.LoadGrammar, and so are the names
+  of the parsers, which show up when tracing and profiling.
+ */
+
+var usage = "Usage: grammargen [-package name] [-o file.go] " +
+  "[-actions Rule,...] grammar.ebnf\n\n" +
+  "Copyright (C) 2018  Armin Heller\n" +
+  "This program comes with ABSOLUTELY NO WARRANTY. This is free software,\n" +
+  "and you are welcome to redistribute it under the conditions of the\n" +
+  "GNU General Public License version 3 or later.\n\n"
+
+func main () {
+  var packageName = flag.String ("package", os.Getenv ("GOPACKAGE"),
+    "the package of the generated code, by default the one of go generate")
+  var output = flag.String ("o", "",
+    "the generated file, by default the grammar file with the extension .go")
+  var actions = flag.String ("actions", "",
+    "comma-separated rules whose results are converted by convert<Rule>")
+  flag.Usage = func () {
+    fmt.Fprint (flag.CommandLine.Output (), usage)
+    flag.PrintDefaults ()
+  }
+  flag.Parse ()
+  if flag.NArg () != 1 {
+    flag.Usage ()
+    os.Exit (2)
+  }
+  var grammarFile = flag.Arg (0)
+  if *packageName == "" {
+    *packageName = "main"
+  }
+  if *output == "" {
+    *output = strings.TrimSuffix (grammarFile, filepath.Ext (grammarFile)) +
+      ".go"
+  }
+  var text, err = os.ReadFile (grammarFile)
+  if err == nil {
+    var source string
+    source, err = generate (string (text), filepath.Base (grammarFile),
+                            *packageName, splitActions (*actions))
+    if err == nil {
+      err = os.WriteFile (*output, []byte (source), 0644)
+    }
+  }
+  if err != nil {
+    fmt.Fprintf (os.Stderr, "grammargen: %s: %v\n", grammarFile, err)
+    os.Exit (1)
+  }
+}
+
+// splitActions turns the comma-separated rules into a set.
+func splitActions (actions string) map[string]bool {
+  var rules = make (map[string]bool)
+  for _, rule := range strings.Split (actions, ",") {
+    if rule = strings.TrimSpace (rule); rule != "" {
+      rules[rule] = true
+    }
+  }
+  return rules
+}
+
+// generate produces the source code of the package with the parsers of the
+// grammar that was read from the file.
+func generate (text string, file string, packageName string,
+               actions map[string]bool) (string, error) {
+  var grammar, err = ReadGrammar (text)
+  if err != nil {
+    return "", err
+  }
+  var defined = make (map[string]bool)
+  for _, rule := range grammar.Rules {
+    // A rule like convertNumber would clash with the action of Number.
+    if !isFunctionName (rule.Name, packageName) ||
+       strings.HasPrefix (rule.Name, "convert") &&
+       actions[strings.TrimPrefix (rule.Name, "convert")] {
+      return "", fmt.Errorf ("the rule %s at %v can't be a Go function",
+        rule.Name, rule.Position)
+    }
+    defined[rule.Name] = true
+  }
+  var unknownActions []string
+  for rule := range actions {
+    if !defined[rule] {
+      unknownActions = append (unknownActions, rule)
+    }
+  }
+  if len (unknownActions) > 0 {
+    sort.Strings (unknownActions)
+    return "", fmt.Errorf ("there are actions for the undefined rules %s",
+      strings.Join (unknownActions, ", "))
+  }
+  var builder strings.Builder
+  fmt.Fprintf (&builder, "// Code generated by grammargen from %s. " +
+    "DO NOT EDIT.\n\n", file)
+  fmt.Fprintf (&builder, "package %s\n\n", packageName)
+  builder.WriteString ("import (\n" +
+    "  \"github.com/QAhell/Parser-Gombinators/parse\"\n)\n")
+  for _, rule := range grammar.Rules {
+    builder.WriteString ("\n")
+    generateRule (&builder, rule, actions[rule.Name])
+  }
+  return builder.String (), nil
+}
+
+// isFunctionName tells whether the generated code can declare a function
+// with the name in the package. The name may neither be a keyword nor
+// predeclared like string or len, because the code of the package might use
+// them, nor may it be the name of the imported package parse or of the
+// parameter input of the generated functions. init and the main function of
+// the package main can't be called like parsers.
+func isFunctionName (name string, packageName string) bool {
+  return !token.IsKeyword (name) && types.Universe.Lookup (name) == nil &&
+    name != "_" && name != "parse" && name != "input" && name != "init" &&
+    !(name == "main" && packageName == "main")
+}
+
+// generateRule writes the function of the rule.
+func generateRule (builder *strings.Builder, rule GrammarRule,
+                   hasAction bool) {
+  writeDefinition (builder, rule)
+  fmt.Fprintf (builder,
+    "func %s (input parse.ParserInput) parse.ParserResult {\n", rule.Name)
+  // Top-level alternatives always get a line of their own, like in the
+  // grammar, and so do the action and the name.
+  var body string
+  if _, isAlternatives := rule.Expression.(GrammarAlternatives);
+     isAlternatives {
+    body = layout (rule.Expression, true, len ("  return "), 6, len ("."))
+  } else {
+    body = format (rule.Expression, true, len ("  return "), 4, len ("."))
+  }
+  if hasAction {
+    body += ".\n    Convert (convert" + rule.Name + ")"
+  }
+  body += ".\n    Named (" + strconv.Quote (rule.Name) + ")"
+  fmt.Fprintf (builder, "  return %s (input)\n}\n", body)
+}
+
+// writeDefinition writes the rule as a comment. Every top-level alternative
+// starts a line, and the elements of long sequences continue on the next one.
+func writeDefinition (builder *strings.Builder, rule GrammarRule) {
+  var alternatives = []GrammarExpression { rule.Expression }
+  if expression, isAlternatives :=
+       rule.Expression.(GrammarAlternatives); isAlternatives {
+    alternatives = expression.Alternatives
+  }
+  var indent = "//" + strings.Repeat (" ", len (" " + rule.Name + " "))
+  var line = "// " + rule.Name + " :="
+  for i, alternative := range alternatives {
+    if i > 0 {
+      builder.WriteString (line + "\n")
+      line = indent + " |"
+    }
+    var elements = []GrammarExpression { alternative }
+    if sequence, isSequence := alternative.(GrammarSequence); isSequence {
+      elements = sequence.Elements
+    }
+    var start = len (line)
+    for j, element := range elements {
+      var text = strings.ReplaceAll (element.String (), "\n", "\\n")
+      if _, isAlternatives := element.(GrammarAlternatives); isAlternatives {
+        text = "(" + text + ")"
+      }
+      if j > 0 && len (line) + len (" " + text) > lineWidth {
+        builder.WriteString (line + "\n")
+        line = "//" + strings.Repeat (" ", start - len ("//"))
+      }
+      line += " " + text
+    }
+  }
+  builder.WriteString (line + "\n")
+}
+
+// lineWidth is the width up to which the generated lines stay on one line.
+const lineWidth = 80
+
+// format produces the expression on one line if it fits between the column
+// and the trailing characters that follow it, otherwise it breaks the
+// expression into lines that are indented by indent spaces. If asReceiver
+// is true then the expression has the type parse.Parser, so that methods
+// can be called on it.
+func format (expression GrammarExpression, asReceiver bool,
+             column int, indent int, trailing int) string {
+  var code = layout (expression, asReceiver, -1, 0, 0)
+  if column < 0 || column + len (code) + trailing <= lineWidth {
+    return code
+  }
+  return layout (expression, asReceiver, column, indent, trailing)
+}
+
+// layout produces the expression and breaks its outermost parts into lines
+// like format. A negative column means that everything stays on one line.
+func layout (expression GrammarExpression, asReceiver bool,
+             column int, indent int, trailing int) string {
+  var breaks = column >= 0
+  var inner = -1
+  if breaks {
+    inner = indent + 2
+  }
+  switch expression := expression.(type) {
+  case GrammarLiteral:
+    return "parse.ExpectString (" + strconv.Quote (expression.Text) + ")"
+  case GrammarClass:
+    var function = "parse.ExpectCodePointIn"
+    if expression.Negated {
+      function = "parse.ExpectCodePointNotIn"
+    }
+    var arguments = []string { strconv.Quote (expression.Description) }
+    for _, codePointRange := range expression.Ranges {
+      arguments = append (arguments, "parse.CodePointRange { First: " +
+        strconv.QuoteRune (codePointRange.First) + ", Last: " +
+        strconv.QuoteRune (codePointRange.Last) + " }")
+    }
+    return function + " (" + join (arguments, breaks, indent + 2) + ")"
+  case GrammarReference:
+    if asReceiver {
+      return "parse.Parser (" + expression.Name + ")"
+    }
+    return expression.Name
+  case GrammarSequence:
+    var elements = make ([]string, len (expression.Elements))
+    for i, element := range expression.Elements {
+      var after = len (",")
+      if i == len (elements) - 1 {
+        after = len (")") + trailing
+      }
+      elements[i] = format (element, false, inner, inner + 2, after)
+    }
+    return "parse.Sequence (" + join (elements, breaks, indent + 2) + ")"
+  case GrammarAlternatives:
+    var alternatives = expression.Alternatives
+    var code = format (alternatives[0], true, column, indent, len ("."))
+    var separator = ""
+    var alternativeColumn = -1
+    if breaks {
+      separator = "\n" + strings.Repeat (" ", indent)
+      alternativeColumn = indent + len ("OrElse (")
+    }
+    for i, alternative := range alternatives[1:] {
+      var after = len (").")
+      if i == len (alternatives) - 2 {
+        after = len (")") + trailing
+      }
+      code += "." + separator + "OrElse (" +
+        format (alternative, false, alternativeColumn, inner, after) + ")"
+    }
+    return code
+  case GrammarRepetition:
+    var method = map[string]string {
+      "*": "Repeated", "+": "OnceOrMore", "?": "Optional" }
+    var call = "." + method[expression.Operator] + " ()"
+    return layout (expression.Expression, true, column, indent,
+                   len (call) + trailing) + call
+  }
+  panic (fmt.Sprintf ("grammargen: unknown expression %v", expression))
+}
+
+// join separates the arguments of a call by commas. If breaks is true then
+// every argument goes on a line of its own, indented by indent spaces.
+func join (arguments []string, breaks bool, indent int) string {
+  if !breaks {
+    return strings.Join (arguments, ", ")
+  }
+  var separator = "\n" + strings.Repeat (" ", indent)
+  return separator + strings.Join (arguments, "," + separator)
+}
@@ -0,0 +1,326 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+  "fmt"
+  "go/parser"
+  "go/token"
+  "os"
+  "os/exec"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+var calculatorGrammar = `
+  Digit        := "0" .. "9"
+  Number       := Digit+
+  Multiplicand := Number
+                | "(" Expression ")"
+  Addend       := Multiplicand (("*" | "/") Multiplicand)*
+  Expression   := Addend (("+" | "-") Addend)*
+  Identifier   := [a-zA-Z_] [a-zA-Z0-9_]*
+`
+
+func TestGenerate (t *testing.T) {
+  var source, err = generate (calculatorGrammar, "calculator.ebnf", "calc",
+                              map[string]bool { "Number": true })
+  if err != nil {
+    t.Fatalf ("Expected the code to be generated, got %v!", err)
+  }
+  if _, err = parser.ParseFile (token.NewFileSet (), "calculator.go", source,
+                                0); err != nil {
+    t.Errorf ("Expected valid Go code, got %v in\n%s", err, source)
+  }
+  var expected = []string {
+    "// Code generated by grammargen from calculator.ebnf. DO NOT EDIT.\n",
+    "package calc\n",
+    "// Multiplicand := Number\n//               | \"(\" Expression \")\"\n",
+    "func Digit (input parse.ParserInput) parse.ParserResult {\n" +
+    "  return parse.ExpectCodePointIn (\n" +
+    "      \"'0' .. '9'\",\n" +
+    "      parse.CodePointRange { First: '0', Last: '9' }).\n" +
+    "    Named (\"Digit\") (input)\n}\n",
+    "  return parse.Parser (Digit).OnceOrMore ().\n" +
+    "    Convert (convertNumber).\n" +
+    "    Named (\"Number\") (input)\n",
+    "  return parse.Parser (Number).\n" +
+    "      OrElse (parse.Sequence (\n" +
+    "          parse.ExpectString (\"(\"),\n" +
+    "          Expression,\n" +
+    "          parse.ExpectString (\")\"))).\n" +
+    "    Named (\"Multiplicand\") (input)\n",
+    "parse.ExpectString (\"*\").OrElse (parse.ExpectString (\"/\"))",
+    "          \"[a-zA-Z_]\",\n" +
+    "          parse.CodePointRange { First: 'a', Last: 'z' },\n" +
+    "          parse.CodePointRange { First: 'A', Last: 'Z' },\n" +
+    "          parse.CodePointRange { First: '_', Last: '_' }),\n",
+  }
+  for _, code := range expected {
+    if !strings.Contains (source, code) {
+      t.Errorf ("Expected the generated code to contain\n%s\ngot\n%s",
+        code, source)
+    }
+  }
+}
+
+func TestGenerateErrors (t *testing.T) {
+  var tests = []struct {
+    grammar string
+    actions map[string]bool
+    expected string
+  } {
+    { "A := B", nil, "parse: the rule B at 1:6 isn't defined" },
+    { "func := 'f'", nil, "the rule func at 1:1 can't be a Go function" },
+    { "string := 'f'", nil,
+      "the rule string at 1:1 can't be a Go function" },
+    { "A := 'a'\ninit := 'i'", nil,
+      "the rule init at 2:1 can't be a Go function" },
+    { "main := 'm'", nil, "the rule main at 1:1 can't be a Go function" },
+    { "input := 'i'", nil, "the rule input at 1:1 can't be a Go function" },
+    { "A := 'a'\nconvertA := 'c'", map[string]bool { "A": true },
+      "the rule convertA at 2:1 can't be a Go function" },
+    { "A := 'a'", map[string]bool { "C": true, "B": true },
+      "there are actions for the undefined rules B, C" },
+  }
+  for _, test := range tests {
+    var _, err = generate (test.grammar, "test.ebnf", "main", test.actions)
+    if err == nil || err.Error () != test.expected {
+      t.Errorf ("Expected the error %s for %s, got %v!",
+        test.expected, test.grammar, err)
+    }
+  }
+}
+
+var statementGrammar = `
+  Statement := "let" Identifier "=" Expression ";"
+             | "print" "(" Expression ("," Expression)* ")" ";"
+             | "if" Expression "then" Statement ("else" Statement)?
+  Keyword   := ("alpha" | "beta" | "gamma" | "delta")
+               ("epsilon" | "zeta" | "eta" | "theta" | "iota")*
+`
+
+func TestGenerateLineWidth (t *testing.T) {
+  var source, err = generate (calculatorGrammar + statementGrammar,
+                              "statement.ebnf", "calc", nil)
+  if err != nil {
+    t.Fatalf ("Expected the code to be generated, got %v!", err)
+  }
+  for _, line := range strings.Split (source, "\n") {
+    if len (line) > lineWidth {
+      t.Errorf ("Expected at most %d characters per line, got\n%s\nin\n%s",
+        lineWidth, line, source)
+    }
+  }
+}
+
+func TestGenerateMainPackage (t *testing.T) {
+  var _, err = generate ("main := 'm'", "main.ebnf", "grammar", nil)
+  if err != nil {
+    t.Errorf ("Expected a rule main outside of the package main, got %v!",
+      err)
+  }
+}
+
+// writeFile writes the file along with its directory.
+func writeFile (t *testing.T, name string, content []byte) {
+  if err := os.MkdirAll (filepath.Dir (name), 0755); err != nil {
+    t.Fatal (err)
+  }
+  if err := os.WriteFile (name, content, 0644); err != nil {
+    t.Fatal (err)
+  }
+}
+
+// copyFile copies the source file to the target.
+func copyFile (t *testing.T, source string, target string) {
+  var content, err = os.ReadFile (source)
+  if err != nil {
+    t.Fatal (err)
+  }
+  writeFile (t, target, content)
+}
+
+// goCommand writes the files into the package calc next to a copy of the
+// parse package in a temporary GOPATH, where the generated code can import
+// it. The command runs the go tool with the arguments in the package calc.
+func goCommand (t *testing.T, files map[string]string,
+                arguments ...string) *exec.Cmd {
+  if testing.Short () {
+    t.Skip ("the go tool takes a while")
+  }
+  var goTool, err = exec.LookPath ("go")
+  if err != nil {
+    t.Skip ("the go tool isn't installed")
+  }
+  var goPath = t.TempDir ()
+  var parsePackage = filepath.Join (goPath, "src", "github.com", "QAhell",
+                                    "Parser-Gombinators", "parse")
+  var directory = filepath.Join (goPath, "src", "calc")
+  var sources []string
+  sources, err = filepath.Glob (filepath.Join ("..", "parse", "*.go"))
+  if err != nil {
+    t.Fatal (err)
+  }
+  for _, source := range sources {
+    if !strings.HasSuffix (source, "_test.go") {
+      copyFile (t, source, filepath.Join (parsePackage,
+                                          filepath.Base (source)))
+    }
+  }
+  for name, content := range files {
+    writeFile (t, filepath.Join (directory, name), []byte (content))
+  }
+  var command = exec.Command (goTool, arguments...)
+  command.Dir = directory
+  command.Env = append (os.Environ (), "GOPATH=" + goPath, "GO111MODULE=off",
+                        "GOFLAGS=")
+  return command
+}
+
+func TestGeneratedCodeVets (t *testing.T) {
+  var source, err = generate (calculatorGrammar + statementGrammar,
+                              "calculator.ebnf", "calc",
+                              map[string]bool { "Number": true })
+  if err != nil {
+    t.Fatalf ("Expected the code to be generated, got %v!", err)
+  }
+  var vet = goCommand (t, map[string]string {
+    "calculator.go": source,
+    "actions.go": "package calc\n\n" +
+      "func convertNumber (digits interface{}) interface{} {\n" +
+      "  return digits\n}\n",
+  }, "vet", ".")
+  var output []byte
+  output, err = vet.CombinedOutput ()
+  if err != nil {
+    t.Errorf ("Expected go vet to accept the generated code, got %v:\n%s\n%s",
+      err, output, source)
+  }
+}
+
+// comparison is a program that applies the generated parsers and those of
+// LoadGrammar to the same inputs. It prints the differences in their results
+// and traces and fails if there are any.
+var comparison = `package main
+
+import (
+  "container/list"
+  "fmt"
+  "os"
+  "reflect"
+  "strings"
+  "github.com/QAhell/Parser-Gombinators/parse"
+)
+
+func convertNumber (digits interface{}) interface{} {
+  var number = 0
+  for element := digits.(*list.List).Front (); element != nil;
+      element = element.Next () {
+    number = 10 * number + int (element.Value.(rune) - '0')
+  }
+  return number
+}
+
+func run (parser parse.Parser, text string) (parse.ParserResult, string) {
+  var trace strings.Builder
+  var stop = parse.TraceTo (&trace)
+  defer stop ()
+  return parser (parse.StringToInput (text)), trace.String ()
+}
+
+func main () {
+  var loaded, err = parse.LoadGrammar (grammar,
+    map[string]func (interface{}) interface{} { "Number": convertNumber })
+  if err != nil {
+    fmt.Println (err)
+    os.Exit (1)
+  }
+  var failed = false
+  for _, test := range tests {
+    var expected, expectedTrace = run (loaded[test.rule], test.input)
+    var actual, actualTrace = run (generated[test.rule], test.input)
+    if !reflect.DeepEqual (actual.Result, expected.Result) ||
+       fmt.Sprint (actual.Error) != fmt.Sprint (expected.Error) ||
+       actual.RemainingInput.Position () !=
+         expected.RemainingInput.Position () ||
+       actual.Committed != expected.Committed {
+      fmt.Printf ("%s on %q: expected %v and %v, got %v and %v\n",
+        test.rule, test.input, expected.Result, expected.Error,
+        actual.Result, actual.Error)
+      failed = true
+    }
+    if actualTrace != expectedTrace {
+      fmt.Printf ("%s on %q: expected the trace\n%sgot\n%s",
+        test.rule, test.input, expectedTrace, actualTrace)
+      failed = true
+    }
+  }
+  if failed {
+    os.Exit (1)
+  }
+}
+`
+
+func TestGeneratedParsersWorkLikeLoadGrammar (t *testing.T) {
+  var grammar = calculatorGrammar + statementGrammar +
+    "  Quoted := '\"' ([^\"\\\\] | \"\\\\\" [\"\\\\nt])* \"\\\"\" \"\\n\"?\n"
+  var tests = [][2]string {
+    { "Expression", "12+3*(45-6)" }, { "Expression", "12*" },
+    { "Expression", "(1" }, { "Expression", "x" },
+    { "Statement", "letx=1;" }, { "Statement", "print(1,2*3);" },
+    { "Statement", "if1thenletx=2;elseprint(3);" },
+    { "Statement", "if1then" }, { "Keyword", "alphaetaiota" },
+    { "Keyword", "betx" }, { "Identifier", "_a1" }, { "Identifier", "1a" },
+    { "Quoted", `"a\"b\\n"` + "\n" }, { "Quoted", `"a\x"` },
+  }
+  var source, err = generate (grammar, "calculator.ebnf", "main",
+                              map[string]bool { "Number": true })
+  if err != nil {
+    t.Fatalf ("Expected the code to be generated, got %v!", err)
+  }
+  var cases strings.Builder
+  var rules = make (map[string]bool)
+  for _, test := range tests {
+    fmt.Fprintf (&cases, "  { %q, %q },\n", test[0], test[1])
+    rules[test[0]] = true
+  }
+  var generated strings.Builder
+  for rule := range rules {
+    fmt.Fprintf (&generated, "  %q: %s,\n", rule, rule)
+  }
+  var program = goCommand (t, map[string]string {
+    "calculator.go": source,
+    "main.go": comparison,
+    "tests.go": fmt.Sprintf ("package main\n\n" +
+      "import \"github.com/QAhell/Parser-Gombinators/parse\"\n\n" +
+      "var grammar = %q\n\n" +
+      "var tests = []struct { rule, input string } {\n%s}\n\n" +
+      "var generated = map[string]parse.Parser {\n%s}\n",
+      grammar, cases.String (), generated.String ()),
+  }, "run", ".")
+  var output []byte
+  output, err = program.CombinedOutput ()
+  if err != nil {
+    t.Errorf ("Expected the generated parsers to work like LoadGrammar, " +
+      "got %v:\n%s", err, output)
+  }
+}
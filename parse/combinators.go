@@ -0,0 +1,221 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "container/list"
+)
+
+// SepBy applies the parser zero or more times with the separator between the
+// parses, e.g. to parse the arguments "a, b, c" of a function call. The
+// results of the parser are accumulated in a list, the results of the
+// separator are dropped. A separator must be followed by another parse.
+func (parser Parser) SepBy (separator Parser) Parser {
+  var elements = parser.SepBy1 (separator)
+  return func (input ParserInput) ParserResult {
+    var result = elements (input)
+    if result.Result == nil && !result.Committed {
+      return ParserResult {
+        Result: list.New (), RemainingInput: input, Error: result.Error }
+    }
+    return result
+  }
+}
+
+// SepBy1 is like SepBy except that it doesn't allow parsing zero times.
+func (parser Parser) SepBy1 (separator Parser) Parser {
+  var rest = separator.AndThen (parser).Second ()
+  return parser.Bind (func (first interface{}) Parser {
+    return func (input ParserInput) ParserResult {
+      var results = list.New ()
+      results.PushBack (first)
+      return rest.repeat (input, results, pushBack)
+    }
+  })
+}
+
+// EndBy applies the parser zero or more times and expects the separator
+// after every parse, e.g. to parse the statements "a; b; c;". The results of
+// the parser are accumulated in a list.
+func (parser Parser) EndBy (separator Parser) Parser {
+  return parser.AndThen (separator).First ().Repeated ()
+}
+
+// Between parses the open, the parser and the close one after the other and
+// produces the result of the parser, e.g. to parse an expression in
+// parentheses.
+func Between (open Parser, close Parser, parser Parser) Parser {
+  return open.AndThen (parser).Second ().AndThen (close).First ()
+}
+
+// ManyTill applies the parser zero or more times until the end parser
+// succeeds, e.g. to parse the contents of a comment up to "*/". The end
+// parser is tried first at every position. The results of the parser are
+// accumulated in a list, the result of the end parser is dropped.
+func (parser Parser) ManyTill (end Parser) Parser {
+  return func (input ParserInput) ParserResult {
+    var result = ParserResult { Result: list.New (), RemainingInput: input }
+    for {
+      var endResult = end (result.RemainingInput)
+      if endResult.Result != nil {
+        result.RemainingInput = endResult.RemainingInput
+        result.Error = mergeErrors (result.Error, endResult.Error)
+        result.Committed = result.Committed || endResult.Committed
+        result.Recovered = concatErrors (result.Recovered, endResult.Recovered)
+        return result
+      }
+      if endResult.Committed {
+        return failedSequence (input, result, endResult)
+      }
+      var oneMoreResult = parser (result.RemainingInput)
+      oneMoreResult.Error = mergeErrors (endResult.Error, oneMoreResult.Error)
+      if oneMoreResult.Result == nil ||
+         !consumed (result.RemainingInput, oneMoreResult.RemainingInput) {
+        oneMoreResult.Result = nil
+        return failedSequence (input, result, oneMoreResult)
+      }
+      result.Result.(*list.List).PushBack (oneMoreResult.Result)
+      result.RemainingInput = oneMoreResult.RemainingInput
+      result.Committed = result.Committed || oneMoreResult.Committed
+      result.Recovered =
+        concatErrors (result.Recovered, oneMoreResult.Recovered)
+    }
+  }
+}
+
+// Count applies the parser exactly n times and accumulates the results in
+// a list. A negative n counts as 0, so Count succeeds without parsing.
+func (parser Parser) Count (n int) Parser {
+  if n < 0 {
+    n = 0
+  }
+  return parser.repeatBetween (n, n)
+}
+
+// AtLeast is like Repeated except that it fails unless the parser succeeds
+// at least n times. A negative n counts as 0.
+func (parser Parser) AtLeast (n int) Parser {
+  return parser.repeatBetween (n, -1)
+}
+
+// AtMost is like Repeated except that it stops after n parses. A negative n
+// counts as 0, so AtMost succeeds without parsing.
+func (parser Parser) AtMost (n int) Parser {
+  if n < 0 {
+    n = 0
+  }
+  return parser.repeatBetween (0, n)
+}
+
+// repeatBetween applies the parser at least minimum and at most maximum
+// times and accumulates the results in a list. A negative maximum means no
+// limit. Like Repeated, it stops as soon as the parser succeeds without
+// consuming any input, but not before the minimum is reached.
+func (parser Parser) repeatBetween (minimum int, maximum int) Parser {
+  return func (input ParserInput) ParserResult {
+    var results = list.New ()
+    var result = ParserResult { Result: results, RemainingInput: input }
+    for maximum < 0 || results.Len () < maximum {
+      var oneMoreResult = parser (result.RemainingInput)
+      if oneMoreResult.Result == nil &&
+         (oneMoreResult.Committed || results.Len () < minimum) {
+        return failedSequence (input, result, oneMoreResult)
+      }
+      result.Error = mergeErrors (result.Error, oneMoreResult.Error)
+      if oneMoreResult.Result == nil ||
+         results.Len () >= minimum &&
+           !consumed (result.RemainingInput, oneMoreResult.RemainingInput) {
+        break
+      }
+      results.PushBack (oneMoreResult.Result)
+      result.RemainingInput = oneMoreResult.RemainingInput
+      result.Committed = result.Committed || oneMoreResult.Committed
+      result.Recovered =
+        concatErrors (result.Recovered, oneMoreResult.Recovered)
+    }
+    return result
+  }
+}
+
+// LookAhead applies the parser without consuming any input: if the parser
+// succeeds then so does LookAhead with the same result, but the remaining
+// input is the input. If the parser fails then so does LookAhead, committed
+// if the parser consumed some input, so wrap it in Try if you'd rather
+// back-track.
+func LookAhead (parser Parser) Parser {
+  return func (input ParserInput) ParserResult {
+    var result = parser (input)
+    if result.Result != nil {
+      result.RemainingInput = input
+    }
+    return result
+  }
+}
+
+// NotFollowedBy succeeds with the result Nothing{} without consuming any
+// input iff the parser fails, e.g. to make sure that a keyword isn't just the
+// beginning of a longer identifier. If the parser succeeds then NotFollowedBy
+// fails without committing and the error shows what the parser found.
+func NotFollowedBy (parser Parser) Parser {
+  return func (input ParserInput) ParserResult {
+    var result = parser (input)
+    if result.Result == nil {
+      return ParserResult { Result: Nothing {}, RemainingInput: input }
+    }
+    var width = offsetOf (result.RemainingInput) - offsetOf (input)
+    if width < 1 {
+      width = 1
+    }
+    return ParserResult {
+      RemainingInput: input, Error: newParseError (input, width, nil) }
+  }
+}
+
+// Chainl1 parses one or more operands with operators between them, like
+// "a - b - c", and groups them to the left: the result is combine (operator,
+// combine (operator, a, b), c) where operator is the result of the operator
+// parser. Use an OperatorTable for more than one level of precedence.
+func (parser Parser) Chainl1 (operator Parser,
+               combine func (operator interface{}, left interface{},
+                             right interface{}) interface{}) Parser {
+  var rest = operator.AndThen (parser)
+  return parser.Bind (func (first interface{}) Parser {
+    return rest.RepeatAndFoldLeft (first,
+      func (left interface{}, result interface{}) interface{} {
+        return combine (GetFirst (result), left, GetSecond (result))
+      })
+  })
+}
+
+// Chainr1 is like Chainl1 except that it groups the operands to the right,
+// like "a ^ b ^ c" as combine (operator, a, combine (operator, b, c)).
+func (parser Parser) Chainr1 (operator Parser,
+               combine func (operator interface{}, left interface{},
+                             right interface{}) interface{}) Parser {
+  var chain Parser
+  chain = parser.Bind (func (left interface{}) Parser {
+    return operator.AndThen (func (input ParserInput) ParserResult {
+      return chain (input)
+    }).Convert (func (result interface{}) interface{} {
+      return combine (GetFirst (result), left, GetSecond (result))
+    }).OrElse (succeed (left))
+  })
+  return chain
+}
@@ -0,0 +1,149 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "container/list"
+  "fmt"
+  "strings"
+  "testing"
+)
+
+// showResult formats results like fmt.Sprint but shows the elements of lists.
+func showResult (result interface{}) string {
+  if results, isList := result.(*list.List); isList {
+    var elements = []string {}
+    for element := results.Front (); element != nil; element = element.Next () {
+      elements = append (elements, showResult (element.Value))
+    }
+    return "[" + strings.Join (elements, " ") + "]"
+  }
+  return fmt.Sprint (result)
+}
+
+// parserTest is an input of a parser and what the parser should make of it.
+type parserTest struct {
+  parser Parser
+  input string
+
+  // expected is the result, formatted by showResult, or the error message.
+  expected string
+
+  // rest is the remaining input.
+  rest string
+  committed bool
+}
+
+// testParsers applies the parsers to their inputs, both in memory and read
+// from a file, and checks the results.
+func testParsers (t *testing.T, tests []parserTest) {
+  for _, test := range tests {
+    for _, input := range []ParserInput {
+        StringToInput (test.input),
+        FileToInput (strings.NewReader (test.input)) } {
+      var result = test.parser (input)
+      var actual = showResult (result.Result)
+      if result.Result == nil && result.Error != nil {
+        actual = result.Error.Error ()
+      }
+      var rest = string ([]rune (test.input)[offsetOf (result.RemainingInput):])
+      if actual != test.expected || rest != test.rest ||
+         result.Committed != test.committed {
+        t.Errorf ("Expected %s with the rest %q and committed %t for %q, " +
+          "got %s with the rest %q and committed %t!", test.expected,
+          test.rest, test.committed, test.input, actual, rest,
+          result.Committed)
+      }
+    }
+  }
+}
+
+// combinatorDigit parses a digit and produces it as a string.
+var combinatorDigit = ExpectCodePointIn ("digit", CodePointRange { '0', '9' }).
+  Convert (func (digit interface{}) interface{} {
+    return string (digit.(rune))
+  })
+
+// bracketOperation builds "(left operator right)".
+func bracketOperation (operator interface{}, left interface{},
+                       right interface{}) interface{} {
+  return fmt.Sprint ("(", left, operator, right, ")")
+}
+
+func TestCombinators (t *testing.T) {
+  var comma = ExpectString (",")
+  var semicolon = ExpectString (";")
+  var minus = ExpectString ("-")
+  var anyCodePoint = ExpectNotCodePoint (nil).
+    Convert (func (codePoint interface{}) interface{} {
+      return string (codePoint.(rune))
+    })
+  var tests = []parserTest {
+    { ExpectIdentifier.SepBy (comma), "a,b,c;", "[a b c]", ";", false },
+    { ExpectIdentifier.SepBy (comma), "", "[]", "", false },
+    { ExpectIdentifier.SepBy (comma), ";", "[]", ";", false },
+    { ExpectIdentifier.SepBy (comma), "a,b,;",
+      "expected identifier at 1:5, found ';'", "a,b,;", true },
+    { ExpectIdentifier.SepBy1 (comma), "a", "[a]", "", false },
+    { ExpectIdentifier.SepBy1 (comma), ";",
+      "expected identifier at 1:1, found ';'", ";", false },
+    { ExpectIdentifier.EndBy (semicolon), "a;b;)", "[a b]", ")", false },
+    { ExpectIdentifier.EndBy (semicolon), "a;b",
+      "expected ';' at end of input", "a;b", true },
+    { Between (ExpectString ("("), ExpectString (")"), ExpectIdentifier),
+      "(a)x", "a", "x", false },
+    { Between (ExpectString ("("), ExpectString (")"), ExpectIdentifier),
+      "(a", "expected ')' at end of input", "(a", true },
+    { anyCodePoint.ManyTill (ExpectString ("*/")), "a*b*/c", "[a * b]", "c",
+      false },
+    { anyCodePoint.ManyTill (ExpectString ("*/")), "*/", "[]", "", false },
+    { anyCodePoint.ManyTill (ExpectString ("*/")), "ab*",
+      "expected '*/' at end of input", "ab*", true },
+    { combinatorDigit.Count (3), "12345", "[1 2 3]", "45", false },
+    { combinatorDigit.Count (0), "1", "[]", "1", false },
+    { combinatorDigit.Count (3), "12",
+      "expected digit at end of input", "12", true },
+    { combinatorDigit.AtLeast (2), "123x", "[1 2 3]", "x", false },
+    { combinatorDigit.AtLeast (2), "1x",
+      "expected digit at 1:2, found 'x'", "1x", true },
+    { combinatorDigit.AtLeast (2), "x",
+      "expected digit at 1:1, found 'x'", "x", false },
+    { combinatorDigit.AtMost (2), "123", "[1 2]", "3", false },
+    { combinatorDigit.AtMost (2), "x", "[]", "x", false },
+    { combinatorDigit.Count (-1), "12", "[]", "12", false },
+    { combinatorDigit.AtLeast (-1), "12", "[1 2]", "", false },
+    { combinatorDigit.AtMost (-1), "12", "[]", "12", false },
+    { LookAhead (combinatorDigit), "1", "1", "1", false },
+    { LookAhead (combinatorDigit), "x",
+      "expected digit at 1:1, found 'x'", "x", false },
+    { NotFollowedBy (combinatorDigit), "x", "{}", "x", false },
+    { NotFollowedBy (ExpectNumber), "12x", "unexpected '12' at 1:1", "12x",
+      false },
+    { combinatorDigit.Chainl1 (minus, bracketOperation), "1-2-3",
+      "((1-2)-3)", "", false },
+    { combinatorDigit.Chainr1 (minus, bracketOperation), "1-2-3",
+      "(1-(2-3))", "", false },
+    { combinatorDigit.Chainl1 (minus, bracketOperation), "1x", "1", "x",
+      false },
+    { combinatorDigit.Chainr1 (minus, bracketOperation), "1-",
+      "expected digit at end of input", "1-", true },
+  }
+  testParsers (t, tests)
+}
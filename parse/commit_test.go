@@ -0,0 +1,112 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "container/list"
+  "testing"
+)
+
+func TestCommittedFailure (t *testing.T) {
+  var parser = ExpectString ("a").AndThen (ExpectString ("b")).
+    OrElse (ExpectString ("a").AndThen (ExpectString ("c")))
+  var input = StringToInput ("ac")
+  var result = parser (input)
+  if result.Result != nil || !result.Committed ||
+     offsetOf (result.RemainingInput) != 0 {
+    t.Errorf ("Expected a committed failure at the start of the input!")
+  }
+  testErrorMessage (t, result, "expected 'b' at 1:2, found 'c'")
+  result = ExpectString ("x").OrElse (ExpectString ("a")) (input)
+  if result.Result != "a" || result.Committed {
+    t.Errorf ("Expected uncommitted failures to try the alternative!")
+  }
+}
+
+func TestTry (t *testing.T) {
+  var parser = Try (ExpectString ("a").AndThen (ExpectString ("b"))).
+    OrElse (ExpectString ("a").AndThen (ExpectString ("c")))
+  var result = parser (StringToInput ("ac"))
+  if result.Result == nil || !result.RemainingInput.AtEnd () {
+    t.Errorf ("Expected Try to allow the alternative!")
+  }
+  result = parser (StringToInput ("ax"))
+  testErrorMessage (t, result, "expected 'b' or 'c' at 1:2, found 'x'")
+}
+
+func TestCut (t *testing.T) {
+  var parser = GetPosition.Cut ().AndThen (ExpectString ("a")).
+    OrElse (ExpectString ("b"))
+  var result = parser (StringToInput ("b"))
+  if result.Result != nil || !result.Committed {
+    t.Errorf ("Expected the Cut to commit the failure!")
+  }
+  result = Try (GetPosition.Cut ().AndThen (ExpectString ("a"))).
+    OrElse (ExpectString ("b")) (StringToInput ("b"))
+  if result.Result != "b" {
+    t.Errorf ("Expected Try to undo the Cut!")
+  }
+}
+
+func TestCommittedRepetitions (t *testing.T) {
+  var pair = ExpectString ("a").AndThen (ExpectString ("b"))
+  var input = StringToInput ("ababa")
+  for _, parser := range []Parser {
+      pair.Repeated (), pair.OnceOrMore (), pair.Optional (),
+      pair.RepeatAndFoldLeft (0, func (count interface{},
+                                       _ interface{}) interface{} {
+        return count.(int) + 1
+      }) } {
+    var result = parser (input.RemainingInput ().RemainingInput ().
+      RemainingInput ().RemainingInput ())
+    if result.Result != nil || !result.Committed {
+      t.Errorf ("Expected the committed failure to stop the parser!")
+    }
+  }
+  var result = Try (pair).Repeated () (input)
+  if result.Result.(*list.List).Len () != 2 ||
+     result.RemainingInput.CurrentCodePoint () != 'a' {
+    t.Errorf ("Expected Try to end the repetition before the last 'a'!")
+  }
+}
+
+func TestRepeatedWithoutProgress (t *testing.T) {
+  var result = ExpectString ("a").Optional ().Repeated () (
+    StringToInput ("aab"))
+  if result.Result.(*list.List).Len () != 2 ||
+     result.RemainingInput.CurrentCodePoint () != 'b' {
+    t.Errorf ("Expected the repetition to stop when it makes no progress!")
+  }
+}
+
+func TestUncommittedFailures (t *testing.T) {
+  var input = StringToInput ("  abx")
+  var reject = func (interface{}) interface{} { return nil }
+  for _, parser := range []Parser {
+      ExpectString ("abc"),
+      MaybeSpacesBefore (ExpectString ("x")),
+      MaybeSpacesBefore (ExpectString ("ab")).Convert (reject) } {
+    var result = parser (input)
+    if result.Result != nil || result.Committed ||
+       offsetOf (result.RemainingInput) != 0 {
+      t.Errorf ("Expected an uncommitted failure at the start of the input!")
+    }
+  }
+}
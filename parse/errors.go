@@ -0,0 +1,175 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "strconv"
+  "strings"
+)
+
+// ParseError describes why a parse failed. Errors of alternatives are merged
+// using the furthest-failure rule: the error that happened further in the
+// input wins and errors at the same position combine their expectations.
+type ParseError struct {
+
+  // Position is where the parse failed.
+  Position Position
+
+  // AtEnd is true if the parse failed because the input ended too early.
+  AtEnd bool
+
+  // Expected lists descriptions of everything that would have been accepted
+  // at the Position. It may be empty if nothing sensible can be said.
+  Expected []string
+
+  // Found describes what was found at the Position instead.
+  Found string
+}
+
+// Error formats the error like "expected ')' or '+' at 1:14, found 'x'".
+func (err *ParseError) Error () string {
+  if len (err.Expected) == 0 {
+    if err.AtEnd {
+      return "unexpected end of input"
+    }
+    return "unexpected " + err.Found + " at " + err.Position.String ()
+  }
+  var expected = "expected " + joinAlternatives (err.Expected)
+  if err.AtEnd {
+    return expected + " at end of input"
+  }
+  return expected + " at " + err.Position.String () + ", found " + err.Found
+}
+
+// joinAlternatives lists the alternatives like "a, b or c".
+func joinAlternatives (alternatives []string) string {
+  if len (alternatives) == 1 {
+    return alternatives[0]
+  }
+  var last = len (alternatives) - 1
+  return strings.Join (alternatives[:last], ", ") + " or " + alternatives[last]
+}
+
+// isBefore returns true iff err happened earlier in the input than other.
+func (err *ParseError) isBefore (other *ParseError) bool {
+  if err.AtEnd || other.AtEnd {
+    return !err.AtEnd
+  }
+  return err.Position.Offset < other.Position.Offset
+}
+
+// isAt returns true iff err happened right at the beginning of the input.
+func (err *ParseError) isAt (input ParserInput) bool {
+  return err.Position.Offset == input.Position ().Offset
+}
+
+// mergeErrors implements the furthest-failure rule. Either argument may be
+// nil, in which case the other one is returned. Errors at the same position
+// describe what was found like the one with expectations, or else like the
+// one that describes more of the input.
+func mergeErrors (first *ParseError, second *ParseError) *ParseError {
+  if first == nil {
+    return second
+  }
+  if second == nil || second.isBefore (first) {
+    return first
+  }
+  if first.isBefore (second) {
+    return second
+  }
+  var expected = append ([]string {}, first.Expected...)
+  for _, alternative := range second.Expected {
+    if !containsString (expected, alternative) {
+      expected = append (expected, alternative)
+    }
+  }
+  // An error without expectations, e.g. of an optional parser, knows less
+  // about what was found than the one that expected something.
+  var found = first.Found
+  if len (first.Expected) == 0 && len (second.Expected) > 0 ||
+     (len (first.Expected) == 0) == (len (second.Expected) == 0) &&
+       len (second.Found) > len (found) {
+    found = second.Found
+  }
+  return &ParseError { first.Position, first.AtEnd, expected, found }
+}
+
+func containsString (texts []string, text string) bool {
+  for _, candidate := range texts {
+    if candidate == text {
+      return true
+    }
+  }
+  return false
+}
+
+// Failure creates the result of a parse that failed at the beginning of the
+// input because none of the expected alternatives could be found. Use it in
+// your own parsers instead of ParserResult { RemainingInput: input } so that
+// the failure comes with a sensible error message.
+func Failure (input ParserInput, expected ...string) ParserResult {
+  return ParserResult {
+    RemainingInput: input, Error: newParseError (input, 1, expected) }
+}
+
+// newParseError creates an error at the beginning of the input. The found
+// text is as long as the width argument but stops at the end of the input.
+// On tokens the found text is the current token.
+func newParseError (input ParserInput, width int,
+                    expected []string) *ParseError {
+  if input.AtEnd () {
+    return &ParseError { input.Position (), true, expected, "end of input" }
+  }
+  if tokens, isTokenInput := underlying (input).(TokenInput); isTokenInput {
+    return &ParseError { input.Position (), false, expected,
+                         quote (tokens.CurrentToken ().Text) }
+  }
+  var builder strings.Builder
+  for remaining := input; !remaining.AtEnd () && width > 0; width-- {
+    builder.WriteRune (remaining.CurrentCodePoint ())
+    remaining = remaining.RemainingInput ()
+  }
+  return &ParseError {
+    input.Position (), false, expected, quote (builder.String ()) }
+}
+
+// quoteReplacer turns Go's double-quote escapes into single-quote escapes.
+var quoteReplacer = strings.NewReplacer ("\\\"", "\"", "'", "\\'")
+
+// quote wraps the text in single quotes and escapes special characters.
+func quote (text string) string {
+  var quoted = strconv.Quote (text)
+  return "'" + quoteReplacer.Replace (quoted[1:len (quoted) - 1]) + "'"
+}
+
+// Expecting replaces the expectations of errors that happened right at the
+// beginning of the input with the description. Use it to name your parsers
+// in error messages, e.g. "expected number" instead of listing digits.
+func (parser Parser) Expecting (description string) Parser {
+  return func (input ParserInput) ParserResult {
+    var result = parser (input)
+    if result.Error != nil && result.Error.isAt (input) {
+      var relabeled = *result.Error
+      relabeled.Expected = []string { description }
+      result.Error = &relabeled
+    }
+    return result
+  }
+}
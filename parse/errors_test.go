@@ -0,0 +1,103 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "strings"
+  "testing"
+)
+
+func testErrorMessage (t *testing.T, result ParserResult, message string) {
+  if result.Error == nil {
+    t.Errorf ("Expected the error \"%s\" but there's no error!", message)
+  } else if result.Error.Error () != message {
+    t.Errorf ("Expected the error \"%s\", got \"%s\"!",
+      message, result.Error.Error ())
+  }
+}
+
+func TestErrorOfSum (t *testing.T) {
+  var parser = ExpectString ("(").AndThen (ExpectNumber).
+    AndThen (ExpectString ("+").AndThen (ExpectNumber).Repeated ()).
+    AndThen (ExpectString (")"))
+  var result = parser (StringToInput ("(1+23+4x"))
+  if result.Result != nil {
+    t.Errorf ("Expected the parser to fail!")
+  }
+  testErrorMessage (t, result, "expected '+' or ')' at 1:8, found 'x'")
+  result = parser (StringToInput ("(1+"))
+  testErrorMessage (t, result, "expected number at end of input")
+}
+
+func TestFurthestFailure (t *testing.T) {
+  var parser = Try (ExpectString ("a").AndThen (ExpectString ("b"))).
+    OrElse (ExpectString ("a").AndThen (ExpectString ("c"))).
+    OrElse (ExpectString ("d"))
+  var result = parser (StringToInput ("ax"))
+  testErrorMessage (t, result, "expected 'b' or 'c' at 1:2, found 'x'")
+  result = parser (StringToInput ("x"))
+  testErrorMessage (t, result, "expected 'a' or 'd' at 1:1, found 'x'")
+}
+
+func TestErrorPosition (t *testing.T) {
+  var parser = ExpectString ("ab\n").Repeated ().AndThen (ExpectString ("!"))
+  var text = "ab\nab\nax"
+  for _, input := range []ParserInput {
+      StringToInput (text), FileToInput (strings.NewReader (text)) } {
+    var result = parser (input)
+    testErrorMessage (t, result,
+      "expected 'ab\\n' or '!' at 3:1, found 'ax'")
+    if result.Error.Position.Offset != 6 {
+      t.Errorf ("Expected the error at offset 6, got %d!",
+        result.Error.Position.Offset)
+    }
+  }
+}
+
+func TestExpecting (t *testing.T) {
+  var parser = ExpectCodePoint ('0').OrElse (ExpectCodePoint ('1')).
+    Expecting ("bit")
+  testErrorMessage (t, parser (StringToInput ("2")),
+    "expected bit at 1:1, found '2'")
+  var twoBits = parser.AndThen (parser).Expecting ("two bits")
+  testErrorMessage (t, twoBits (StringToInput ("02")),
+    "expected bit at 1:2, found '2'")
+}
+
+func TestFailure (t *testing.T) {
+  testErrorMessage (t, Fail (StringToInput ("'")), "unexpected '\\'' at 1:1")
+  testErrorMessage (t, Failure (StringToInput (""), "x"), "expected x at end of input")
+  var result = ExpectIdentifier.Convert (
+    func (interface{}) interface{} { return nil }) (StringToInput ("a b"))
+  if result.Result != nil {
+    t.Errorf ("Expected the converter to reject the identifier!")
+  }
+  testErrorMessage (t, result, "unexpected 'a' at 1:1")
+}
+
+func TestErrorFoundAfterOptionalParser (t *testing.T) {
+  var result = MaybeSpacesBefore (ExpectInt64) (
+    StringToInput ("99999999999999999999"))
+  if result.Error == nil ||
+     result.Error.Found != "'99999999999999999999'" {
+    t.Errorf ("Expected the error to show the whole literal, got %v!",
+      result.Error)
+  }
+}
@@ -0,0 +1,550 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "container/list"
+  "fmt"
+  "strings"
+)
+
+// Grammar is a grammar in EBNF as read by ReadGrammar:
+//
+//   Digit        := "0" .. "9"
+//   Number       := Digit+
+//   Multiplicand := Number
+//                 | "(" Expression ")"
+//   Addend       := Multiplicand (("*" | "/") Multiplicand)*
+//   Expression   := Addend (("+" | "-") Addend)* ;
+//
+// Every rule starts with its name and ":=", the semicolon at its end is
+// optional. Rules may refer to rules that are defined later on. Literals are
+// written in double or single quotes, "a" .. "z" is a range of code points and
+// [a-zA-Z_] or [^"\\] are character classes. Backslashes escape quotes,
+// brackets and backslashes, \n, \t and \r are the usual control characters.
+// Juxtaposition means sequence, "|" alternative, "*" zero or more, "+" once or
+// more and "?" zero or one time.
+type Grammar struct {
+
+  // Rules are the rules in the order of their definition.
+  Rules []GrammarRule
+}
+
+// GrammarRule is the definition of a rule.
+type GrammarRule struct {
+
+  // Name is the name of the rule.
+  Name string
+
+  // Position is where the definition starts in the grammar.
+  Position Position
+
+  // Expression is the right-hand side of the rule.
+  Expression GrammarExpression
+}
+
+// String formats the rule in EBNF.
+func (rule GrammarRule) String () string {
+  return rule.Name + " := " + rule.Expression.String ()
+}
+
+// GrammarExpression is a GrammarLiteral, GrammarClass, GrammarReference,
+// GrammarSequence, GrammarAlternatives or GrammarRepetition.
+type GrammarExpression interface {
+
+  // String formats the expression in EBNF.
+  String () string
+
+  // compile turns the expression into a parser that calls the rules of the
+  // loader.
+  compile (loader *grammarLoader) Parser
+}
+
+// GrammarLiteral matches the Text exactly, see ExpectString.
+type GrammarLiteral struct {
+
+  // Text is the literal without quotes and escapes.
+  Text string
+}
+
+// GrammarClass matches a code point in one of the Ranges, or in none of them
+// if the class is Negated, see ExpectCodePointIn.
+type GrammarClass struct {
+
+  // Description is the class in the notation of the grammar, e.g. [a-z].
+  Description string
+
+  // Negated is true for classes like [^a-z].
+  Negated bool
+
+  // Ranges are the code points of the class, single code points are ranges
+  // of their own.
+  Ranges []CodePointRange
+}
+
+// GrammarReference applies the rule with the Name.
+type GrammarReference struct {
+
+  // Name is the name of the rule.
+  Name string
+
+  // Position is where the reference is in the grammar.
+  Position Position
+}
+
+// GrammarSequence applies the Elements one after the other, see Sequence.
+type GrammarSequence struct {
+
+  // Elements are the parts of the sequence in their order.
+  Elements []GrammarExpression
+}
+
+// GrammarAlternatives tries the Alternatives in order, see OrElse.
+type GrammarAlternatives struct {
+
+  // Alternatives are the alternatives in the order in which they are tried.
+  Alternatives []GrammarExpression
+}
+
+// GrammarRepetition applies the Expression several times, depending on the
+// Operator "*", "+" or "?", see Repeated, OnceOrMore and Optional.
+type GrammarRepetition struct {
+
+  // Expression is the repeated expression.
+  Expression GrammarExpression
+
+  // Operator is "*", "+" or "?".
+  Operator string
+}
+
+// String writes the literal in double quotes so that ReadGrammar can read it
+// again: it only escapes quotes, backslashes, \n, \t and \r.
+func (literal GrammarLiteral) String () string {
+  var builder strings.Builder
+  builder.WriteRune ('"')
+  for _, codePoint := range literal.Text {
+    switch codePoint {
+    case '"', '\\':
+      builder.WriteRune ('\\')
+      builder.WriteRune (codePoint)
+    case '\n':
+      builder.WriteString (`\n`)
+    case '\t':
+      builder.WriteString (`\t`)
+    case '\r':
+      builder.WriteString (`\r`)
+    default:
+      builder.WriteRune (codePoint)
+    }
+  }
+  builder.WriteRune ('"')
+  return builder.String ()
+}
+
+func (class GrammarClass) String () string {
+  return class.Description
+}
+
+func (reference GrammarReference) String () string {
+  return reference.Name
+}
+
+func (sequence GrammarSequence) String () string {
+  var elements = make ([]string, len (sequence.Elements))
+  for i, element := range sequence.Elements {
+    elements[i] = element.String ()
+    if _, isAlternatives := element.(GrammarAlternatives); isAlternatives {
+      elements[i] = "(" + elements[i] + ")"
+    }
+  }
+  return strings.Join (elements, " ")
+}
+
+func (alternatives GrammarAlternatives) String () string {
+  var texts = make ([]string, len (alternatives.Alternatives))
+  for i, alternative := range alternatives.Alternatives {
+    texts[i] = alternative.String ()
+  }
+  return strings.Join (texts, " | ")
+}
+
+func (repetition GrammarRepetition) String () string {
+  switch repetition.Expression.(type) {
+  case GrammarSequence, GrammarAlternatives:
+    return "(" + repetition.Expression.String () + ")" + repetition.Operator
+  }
+  return repetition.Expression.String () + repetition.Operator
+}
+
+// ReadGrammar reads a grammar in EBNF, see Grammar. It fails if the grammar
+// can't be parsed, if a rule is defined twice or if an undefined rule is
+// referenced.
+func ReadGrammar (grammar string) (*Grammar, error) {
+  var result = Parser (grammarDefinition).OnceOrMore ().
+    AndThen (ExpectSpaces).First ().ParseAll (StringToInput (grammar))
+  if result.Result == nil {
+    return nil, result.Error
+  }
+  var rules []GrammarRule
+  var defined = make (map[string]bool)
+  for element := result.Result.(*list.List).Front (); element != nil;
+      element = element.Next () {
+    var rule = element.Value.(GrammarRule)
+    if defined[rule.Name] {
+      return nil, fmt.Errorf ("parse: the rule %s at %v is already defined",
+        rule.Name, rule.Position)
+    }
+    defined[rule.Name] = true
+    rules = append (rules, rule)
+  }
+  for _, rule := range rules {
+    var undefined = undefinedReference (rule.Expression, defined)
+    if undefined != nil {
+      return nil, fmt.Errorf ("parse: the rule %s at %v isn't defined",
+        undefined.Name, undefined.Position)
+    }
+  }
+  return &Grammar { rules }, nil
+}
+
+// undefinedReference finds the first reference in the expression to a rule
+// that isn't defined.
+func undefinedReference (expression GrammarExpression,
+                         defined map[string]bool) *GrammarReference {
+  var subexpressions []GrammarExpression
+  switch expression := expression.(type) {
+  case GrammarReference:
+    if !defined[expression.Name] {
+      return &expression
+    }
+  case GrammarSequence:
+    subexpressions = expression.Elements
+  case GrammarAlternatives:
+    subexpressions = expression.Alternatives
+  case GrammarRepetition:
+    subexpressions = []GrammarExpression { expression.Expression }
+  }
+  for _, subexpression := range subexpressions {
+    var undefined = undefinedReference (subexpression, defined)
+    if undefined != nil {
+      return undefined
+    }
+  }
+  return nil
+}
+
+// LoadGrammar reads a grammar in EBNF and returns a parser for each of its
+// rules, so that grammars can be shipped as data instead of code. See
+// Grammar for the notation. The parsers don't skip any spaces by themselves.
+//
+// The alternatives behave like OrElse: no back-tracking after an alternative
+// has consumed some input. Literals produce strings, ranges and character
+// classes produce runes, sequences of several parsers produce
+// []interface{}, "*" and "+" produce a *list.List and "?" produces Nothing{}
+// if it doesn't find anything. The actions convert the results of the rules
+// with the same names, like Convert, and the other rules refer to the
+// converted results. The parsers are named after their rules, see Named.
+func LoadGrammar (grammar string,
+                  actions map[string]func (interface{}) interface{}) (
+                  map[string]Parser, error) {
+  var rules, err = ReadGrammar (grammar)
+  if err != nil {
+    return nil, err
+  }
+  return rules.Parsers (actions)
+}
+
+// Parsers turns the rules of the grammar into parsers, see LoadGrammar.
+func (grammar *Grammar) Parsers (
+          actions map[string]func (interface{}) interface{}) (
+          map[string]Parser, error) {
+  var loader = &grammarLoader { make (map[string]*Parser) }
+  var parsers = make (map[string]Parser)
+  for _, rule := range grammar.Rules {
+    parsers[rule.Name] = rule.Expression.compile (loader)
+  }
+  for name, action := range actions {
+    var parser, isDefined = parsers[name]
+    if !isDefined {
+      return nil, fmt.Errorf ("parse: there's an action for the rule %s " +
+        "which isn't defined", name)
+    }
+    parsers[name] = parser.Convert (action)
+  }
+  for name, parser := range parsers {
+    parsers[name] = parser.Named (name)
+    *loader.rule (name) = parsers[name]
+  }
+  return parsers, nil
+}
+
+// grammarLoader holds the parsers that the references of a grammar call.
+// They are filled in after all rules have been compiled.
+type grammarLoader struct {
+  rules map[string]*Parser
+}
+
+// rule returns the place of the parser of the named rule.
+func (loader *grammarLoader) rule (name string) *Parser {
+  var rule, isKnown = loader.rules[name]
+  if !isKnown {
+    rule = new (Parser)
+    loader.rules[name] = rule
+  }
+  return rule
+}
+
+func (literal GrammarLiteral) compile (loader *grammarLoader) Parser {
+  return ExpectString (literal.Text)
+}
+
+func (class GrammarClass) compile (loader *grammarLoader) Parser {
+  if class.Negated {
+    return ExpectCodePointNotIn (class.Description, class.Ranges...)
+  }
+  return ExpectCodePointIn (class.Description, class.Ranges...)
+}
+
+func (reference GrammarReference) compile (loader *grammarLoader) Parser {
+  var rule = loader.rule (reference.Name)
+  return func (input ParserInput) ParserResult {
+    return (*rule) (input)
+  }
+}
+
+func (sequence GrammarSequence) compile (loader *grammarLoader) Parser {
+  var parsers = make ([]Parser, len (sequence.Elements))
+  for i, element := range sequence.Elements {
+    parsers[i] = element.compile (loader)
+  }
+  return Sequence (parsers...)
+}
+
+func (alternatives GrammarAlternatives) compile (
+                                          loader *grammarLoader) Parser {
+  var parser = alternatives.Alternatives[0].compile (loader)
+  for _, alternative := range alternatives.Alternatives[1:] {
+    parser = parser.OrElse (alternative.compile (loader))
+  }
+  return parser
+}
+
+func (repetition GrammarRepetition) compile (loader *grammarLoader) Parser {
+  var parser = repetition.Expression.compile (loader)
+  switch repetition.Operator {
+  case "*":
+    return parser.Repeated ()
+  case "+":
+    return parser.OnceOrMore ()
+  }
+  return parser.Optional ()
+}
+
+// grammarSymbol parses a symbol of the grammar notation.
+func grammarSymbol (symbol string) Parser {
+  return MaybeSpacesBefore (ExpectString (symbol))
+}
+
+// grammarName parses the name of a rule along with its position.
+var grammarName Parser =
+  MaybeSpacesBefore (GetPosition.AndThen (ExpectIdentifier))
+
+// grammarDefinition parses Name := Expression ;
+func grammarDefinition (input ParserInput) ParserResult {
+  return grammarName.AndThen (grammarSymbol (":=")).First ().
+    AndThen (grammarExpression).
+    AndThen (grammarSymbol (";").Optional ()).First ().
+    Convert (func (result interface{}) interface{} {
+      var name = GetFirst (result).(Pair)
+      return GrammarRule { name.Second.(string), name.First.(Position),
+                           GetSecond (result).(GrammarExpression) }
+    }) (input)
+}
+
+// grammarExpression parses alternatives separated by "|".
+func grammarExpression (input ParserInput) ParserResult {
+  return Parser (grammarSequence).
+    AndThen (grammarSymbol ("|").AndThen (grammarSequence).Second ().
+      Repeated ()).
+    Convert (func (result interface{}) interface{} {
+      var alternatives = []GrammarExpression {
+        GetFirst (result).(GrammarExpression) }
+      for element := GetSecond (result).(*list.List).Front (); element != nil;
+          element = element.Next () {
+        alternatives = append (alternatives,
+                               element.Value.(GrammarExpression))
+      }
+      if len (alternatives) == 1 {
+        return alternatives[0]
+      }
+      return GrammarAlternatives { alternatives }
+    }) (input)
+}
+
+// grammarSequence parses one or more factors in a row.
+func grammarSequence (input ParserInput) ParserResult {
+  return Parser (grammarFactor).OnceOrMore ().
+    Convert (func (factors interface{}) interface{} {
+      var elements []GrammarExpression
+      for element := factors.(*list.List).Front (); element != nil;
+          element = element.Next () {
+        elements = append (elements, element.Value.(GrammarExpression))
+      }
+      if len (elements) == 1 {
+        return elements[0]
+      }
+      return GrammarSequence { elements }
+    }) (input)
+}
+
+// grammarFactor parses a primary followed by any number of "*", "+" and "?".
+func grammarFactor (input ParserInput) ParserResult {
+  return Parser (grammarPrimary).Bind (func (primary interface{}) Parser {
+    return grammarSymbol ("*").OrElse (grammarSymbol ("+")).
+      OrElse (grammarSymbol ("?")).RepeatAndFoldLeft (primary,
+        func (expression interface{}, operator interface{}) interface{} {
+          return GrammarRepetition { expression.(GrammarExpression),
+                                     operator.(string) }
+        })
+  }) (input)
+}
+
+// grammarPrimary parses a literal, a range, a character class, an expression
+// in parentheses or a reference to a rule.
+func grammarPrimary (input ParserInput) ParserResult {
+  return grammarLiteralOrRange.
+    OrElse (MaybeSpacesBefore (grammarCharacterClass)).
+    OrElse (grammarSymbol ("(").AndThen (grammarExpression).
+      AndThen (grammarSymbol (")")).First ().Second ()).
+    OrElse (grammarReference) (input)
+}
+
+// grammarReference parses the name of a rule unless it starts the next
+// definition.
+var grammarReference Parser = Try (grammarName.AndThen (notDefinition)).
+  First ().Convert (func (result interface{}) interface{} {
+    return GrammarReference { result.(Pair).Second.(string),
+                              result.(Pair).First.(Position) }
+  })
+
+// notDefinition succeeds without consuming anything unless the input
+// continues with ":=".
+var notDefinition Parser = NotFollowedBy (grammarSymbol (":="))
+
+// grammarCodePoint parses a code point of a literal or a character class,
+// which may be escaped by a backslash. The terminator must be escaped.
+func grammarCodePoint (terminator rune) Parser {
+  return ExpectCodePoint ('\\').AndThen (ExpectNotCodePoint (nil)).Second ().
+    Convert (unescape).
+    OrElse (ExpectNotCodePoint ([]rune { terminator }))
+}
+
+// unescape turns the code point after a backslash into the escaped one.
+func unescape (codePoint interface{}) interface{} {
+  switch codePoint.(rune) {
+  case 'n':
+    return '\n'
+  case 't':
+    return '\t'
+  case 'r':
+    return '\r'
+  }
+  return codePoint
+}
+
+// quotedText parses text between two quotes.
+func quotedText (quote rune) Parser {
+  return ExpectCodePoint (quote).
+    AndThen (grammarCodePoint (quote).Repeated ()).
+    AndThen (ExpectCodePoint (quote)).First ().Second ().
+    Convert (func (codePoints interface{}) interface{} {
+      var builder strings.Builder
+      for element := codePoints.(*list.List).Front (); element != nil;
+          element = element.Next () {
+        builder.WriteRune (element.Value.(rune))
+      }
+      return builder.String ()
+    })
+}
+
+// grammarLiteral parses a literal in double or single quotes.
+var grammarLiteral Parser =
+  quotedText ('"').OrElse (quotedText ('\'')).Expecting ("literal")
+
+// singleCodePointLiteral parses a literal with exactly one code point.
+var singleCodePointLiteral Parser = func (input ParserInput) ParserResult {
+  var result = grammarLiteral (input)
+  if result.Result != nil && len ([]rune (result.Result.(string))) != 1 {
+    return Failure (input, "literal with a single code point")
+  }
+  return result
+}
+
+// grammarLiteralOrRange parses a literal or a range like "a" .. "z".
+var grammarLiteralOrRange Parser =
+  MaybeSpacesBefore (grammarLiteral).Bind (func (text interface{}) Parser {
+    var literal = succeed (GrammarLiteral { text.(string) })
+    var first = []rune (text.(string))
+    if len (first) != 1 {
+      return literal
+    }
+    return grammarSymbol ("..").
+      AndThen (MaybeSpacesBefore (singleCodePointLiteral)).Second ().
+      Convert (func (text interface{}) interface{} {
+        var last = []rune (text.(string))[0]
+        return GrammarClass {
+          quote (string (first)) + " .. " + quote (string (last)), false,
+          []CodePointRange { { first[0], last } } }
+      }).OrElse (literal)
+  })
+
+// grammarClassRange parses a code point or a range like a-z in a character
+// class.
+var grammarClassRange Parser = grammarCodePoint (']').
+  AndThen (Try (ExpectCodePoint ('-').AndThen (grammarCodePoint (']'))).
+    Second ().Optional ()).
+  Convert (func (result interface{}) interface{} {
+    var first = GetFirst (result).(rune)
+    var last, isRange = GetSecond (result).(rune)
+    if !isRange {
+      last = first
+    }
+    return CodePointRange { first, last }
+  })
+
+// grammarCharacterClass parses a character class like [a-z_] or [^"].
+var grammarCharacterClass Parser = func (input ParserInput) ParserResult {
+  var result = ExpectCodePoint ('[').
+    AndThen (ExpectCodePoint ('^').Optional ()).Second ().
+    AndThen (grammarClassRange.OnceOrMore ()).
+    AndThen (ExpectCodePoint (']')).First ().
+    Expecting ("character class") (input)
+  if result.Result != nil {
+    var _, isNegated = GetFirst (result.Result).(rune)
+    var ranges []CodePointRange
+    for element := GetSecond (result.Result).(*list.List).Front ();
+        element != nil; element = element.Next () {
+      ranges = append (ranges, element.Value.(CodePointRange))
+    }
+    result.Result = GrammarClass {
+      textBetween (input, result.RemainingInput), isNegated, ranges }
+  }
+  return result
+}
@@ -0,0 +1,223 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "container/list"
+  "strconv"
+  "strings"
+  "testing"
+)
+
+var arithmeticGrammar = `
+  Digit        := "0" .. "9"
+  Number       := Digit+
+  Multiplicand := Number
+                | "(" Expression ")"
+  Addend       := Multiplicand (("*" | "/") Multiplicand)*
+  Expression   := Addend (('+' | '-') Addend)* ;
+`
+
+// foldOperations computes a [first, [[operator, operand]...]] sequence.
+func foldOperations (result interface{}) interface{} {
+  var sequence = result.([]interface{})
+  var value = sequence[0].(int)
+  for element := sequence[1].(*list.List).Front (); element != nil;
+      element = element.Next () {
+    var operation = element.Value.([]interface{})
+    var operand = operation[1].(int)
+    switch operation[0] {
+    case "+":
+      value += operand
+    case "-":
+      value -= operand
+    case "*":
+      value *= operand
+    case "/":
+      value /= operand
+    }
+  }
+  return value
+}
+
+var arithmeticActions = map[string]func (interface{}) interface{} {
+  "Number": func (digits interface{}) interface{} {
+    var builder strings.Builder
+    for element := digits.(*list.List).Front (); element != nil;
+        element = element.Next () {
+      builder.WriteRune (element.Value.(rune))
+    }
+    var number, _ = strconv.Atoi (builder.String ())
+    return number
+  },
+  "Multiplicand": func (result interface{}) interface{} {
+    var parenthesized, isParenthesized = result.([]interface{})
+    if isParenthesized {
+      return parenthesized[1]
+    }
+    return result
+  },
+  "Addend": foldOperations,
+  "Expression": foldOperations,
+}
+
+func TestLoadGrammar (t *testing.T) {
+  var rules, err = LoadGrammar (arithmeticGrammar, arithmeticActions)
+  if err != nil {
+    t.Fatalf ("Expected the grammar to load, got %v!", err)
+  }
+  var tests = map[string]int {
+    "42": 42,
+    "1+2*3": 7,
+    "2*(3+4)-5": 9,
+    "100/(2*5)/5": 2,
+  }
+  for text, expected := range tests {
+    var result = rules["Expression"].ParseAll (StringToInput (text))
+    if result.Result != expected {
+      t.Errorf ("Expected %s to be %d, got %v and %v!",
+        text, expected, result.Result, result.Error)
+    }
+  }
+  var result = rules["Expression"].ParseAll (StringToInput ("1+(2*x)"))
+  if result.Error == nil || result.Error.Position.Offset != 5 {
+    t.Errorf ("Expected an error in front of x, got %v!", result.Error)
+  }
+}
+
+func TestCharacterClasses (t *testing.T) {
+  var rules, err = LoadGrammar (`
+    Identifier := [a-zA-Z_][a-zA-Z0-9_]*
+    String     := ["] ([^"\\] | [\\] ["])* ["]
+    Minus      := [^-a-z] [a-z-]
+  `, nil)
+  if err != nil {
+    t.Fatalf ("Expected the grammar to load, got %v!", err)
+  }
+  var tests = []struct {
+    rule, text string
+    accepted bool
+  } {
+    { "Identifier", "snake_case_42", true },
+    { "Identifier", "42nd", false },
+    { "String", `"say \"hi\""`, true },
+    { "String", `"unterminated`, false },
+    { "Minus", "+-", true },
+    { "Minus", "-a", false },
+  }
+  for _, test := range tests {
+    var result = rules[test.rule].ParseAll (StringToInput (test.text))
+    if (result.Result != nil) != test.accepted {
+      t.Errorf ("Expected %s to accept %s: %v, got %v!",
+        test.rule, test.text, test.accepted, result.Error)
+    }
+  }
+  var result = rules["Identifier"] (StringToInput ("42"))
+  if result.Error == nil || result.Error.Error () !=
+     "expected [a-zA-Z_] at 1:1, found '4'" {
+    t.Errorf ("Expected the character class in the error, got %v!",
+      result.Error)
+  }
+}
+
+func TestGrammarResults (t *testing.T) {
+  var rules, err = LoadGrammar (`
+    Pair   := Letter "=" Letter ;
+    Maybe  := "x"? ;
+    Letter := 'a' .. 'c'
+  `, nil)
+  if err != nil {
+    t.Fatalf ("Expected the grammar to load, got %v!", err)
+  }
+  var pair = rules["Pair"] (StringToInput ("a=c")).Result.([]interface{})
+  if len (pair) != 3 || pair[0] != 'a' || pair[1] != "=" || pair[2] != 'c' {
+    t.Errorf ("Expected [a = c], got %v!", pair)
+  }
+  if rules["Maybe"] (StringToInput ("y")).Result != (Nothing {}) {
+    t.Errorf ("Expected Nothing for a missing optional part!")
+  }
+}
+
+func TestGrammarErrors (t *testing.T) {
+  var tests = []struct {
+    grammar string
+    actions map[string]func (interface{}) interface{}
+    expected string
+  } {
+    { "A := B", nil, "parse: the rule B at 1:6 isn't defined" },
+    { "A := 'a'\nA := 'b'", nil,
+      "parse: the rule A at 2:1 is already defined" },
+    { "A := 'a'", map[string]func (interface{}) interface{} {
+        "Addend": foldOperations },
+      "parse: there's an action for the rule Addend which isn't defined" },
+    { "A := 'a' | ", nil, "expected literal, character class, '(' or " +
+      "identifier at end of input" },
+    { `A := "a" .. "bc"`, nil,
+      "expected literal with a single code point at 1:13, found '\"'" },
+  }
+  for _, test := range tests {
+    var _, err = LoadGrammar (test.grammar, test.actions)
+    if err == nil || err.Error () != test.expected {
+      t.Errorf ("Expected the error %s for %s, got %v!",
+        test.expected, test.grammar, err)
+    }
+  }
+}
+
+func TestReadGrammar (t *testing.T) {
+  var grammar, err = ReadGrammar (arithmeticGrammar)
+  if err != nil {
+    t.Fatalf ("Expected the grammar to be read, got %v!", err)
+  }
+  var expected = []string {
+    `Digit := '0' .. '9'`,
+    `Number := Digit+`,
+    `Multiplicand := Number | "(" Expression ")"`,
+    `Addend := Multiplicand (("*" | "/") Multiplicand)*`,
+    `Expression := Addend (("+" | "-") Addend)*`,
+  }
+  if len (grammar.Rules) != len (expected) {
+    t.Fatalf ("Expected %d rules, got %d!",
+      len (expected), len (grammar.Rules))
+  }
+  for i, rule := range grammar.Rules {
+    if rule.String () != expected[i] {
+      t.Errorf ("Expected the rule %s, got %s!", expected[i], rule)
+    }
+  }
+  if grammar.Rules[2].Position.String () != "4:3" {
+    t.Errorf ("Expected Multiplicand at 4:3, got %v!",
+      grammar.Rules[2].Position)
+  }
+}
+
+func TestGrammarLiteralsReadBack (t *testing.T) {
+  var text = "a\"b\\c'\n\t\r\x01\u2028熊"
+  var grammar, err = ReadGrammar (
+    "Literal := " + GrammarLiteral { text }.String ())
+  if err != nil {
+    t.Fatalf ("Expected the literal to be read, got %v!", err)
+  }
+  var literal, isLiteral = grammar.Rules[0].Expression.(GrammarLiteral)
+  if !isLiteral || literal.Text != text {
+    t.Errorf ("Expected the literal %q, got %v!", text,
+      grammar.Rules[0].Expression)
+  }
+}
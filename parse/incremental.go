@@ -0,0 +1,218 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "fmt"
+  "sort"
+)
+
+// Document is a text that changes by small edits, e.g. in an editor, and
+// that is parsed again after every edit. The memoized parsers remember their
+// results between the parses of a Document: after an edit only the memoized
+// parsers that looked at the edited part of the text run again, the others
+// reuse their results, shifted to the new location of their part of the
+// text. That's why the results of memoized parsers shouldn't contain
+// absolute positions, e.g. from WithSpan, unless they are recomputed outside
+// of the memoized parsers. Errors and remaining inputs are shifted properly.
+//
+//   var document = NewDocument (text)
+//   var result = parser (document.Input ())
+//   document.Edit (10, 12, "new text")
+//   result = parser (document.Input ())
+type Document struct {
+  text []rune
+  lines *lineIndex
+  table *memoTable
+
+  // rules split the text into tokens, unless they are nil
+  rules []LexerRule
+
+  // tokens are the tokens of the text if there are rules
+  tokens []Token
+}
+
+// NewDocument creates a document with the text.
+func NewDocument (text string) *Document {
+  return &Document { []rune (text), &lineIndex {}, newMemoTable (), nil, nil }
+}
+
+// NewTokenDocument creates a document whose text the rules split into tokens
+// just like Lexer, so that the memoized parsers work on a TokenInput. After
+// an edit the rules only split the text again from the last token before
+// the edit until they reach one of the previous tokens after the edit, so
+// they should split the text the same way from the beginning of every token.
+// If no rule matches at some position then there's a token without a kind
+// there, on which every parser fails, and the next token starts after its
+// first code point.
+func NewTokenDocument (text string, rules ...LexerRule) *Document {
+  var document = NewDocument (text)
+  document.rules = rules
+  document.tokens, _ = document.lex (0, nil)
+  return document
+}
+
+// Text returns the current text of the document.
+func (document *Document) Text () string {
+  return string (document.text)
+}
+
+// Input returns the current text as a MemoInput that shares the memoized
+// results with the previous parses of the document.
+func (document *Document) Input () ParserInput {
+  return document.inputAt (0)
+}
+
+// inputAt returns the input at the offset. On tokens it's the input at the
+// first token that starts at the offset or after it.
+func (document *Document) inputAt (offset int) ParserInput {
+  if document.rules == nil {
+    return MemoInput { document.textAt (offset), document.table, offset }
+  }
+  var tokens = TokenInput { document.tokens, sort.Search (len (document.tokens),
+    func (i int) bool {
+      return document.tokens[i].Span.Start.Offset >= offset
+    }) }
+  return MemoInput { tokens, document.table, offsetOf (tokens) }
+}
+
+// textAt returns the code points of the text from the offset on.
+func (document *Document) textAt (offset int) ParserInput {
+  return indexedRuneArrayInput {
+    RuneArrayInput { document.text, offset }, document.lines }
+}
+
+// lex splits the text from the offset on into tokens until it reaches the
+// end of the text or an input for which stop is true, which it returns.
+func (document *Document) lex (offset int,
+                               stop func (ParserInput) bool) ([]Token,
+                                                             ParserInput) {
+  var tokens []Token
+  var input = document.textAt (offset)
+  for {
+    var lexed, remainingInput, _ = splitTokens (document.rules, input, stop)
+    tokens = append (tokens, lexed...)
+    if remainingInput.AtEnd () || stop != nil && stop (remainingInput) {
+      return tokens, remainingInput
+    }
+    input = remainingInput.RemainingInput ()
+    tokens = append (tokens, Token { "", textBetween (remainingInput, input),
+                                     spanBetween (remainingInput, input) })
+  }
+}
+
+// relex replaces the tokens after the code points from the offset start up
+// to the offset end were replaced by inserted code points. It returns the
+// offset in the previous text from which on the tokens are the same as
+// before, just moved by delta code points.
+func (document *Document) relex (start int, end int, delta int) int {
+  var previous = document.tokens
+  var first = sort.Search (len (previous), func (i int) bool {
+    return previous[i].Span.End.Offset >= start
+  })
+  var offset = 0
+  if first > 0 {
+    first--
+    offset = previous[first].Span.Start.Offset
+  }
+  var next = sort.Search (len (previous), func (i int) bool {
+    return previous[i].Span.Start.Offset >= end
+  })
+  var tokens, remainingInput = document.lex (offset,
+    func (input ParserInput) bool {
+      var offset = offsetOf (input) - delta
+      for next < len (previous) && previous[next].Span.Start.Offset < offset {
+        next++
+      }
+      return offset >= end && next < len (previous) &&
+        previous[next].Span.Start.Offset == offset
+    })
+  tokens = append (previous[:first:first], tokens...)
+  if remainingInput.AtEnd () {
+    document.tokens = tokens
+    return len (document.text) - delta
+  }
+  for _, token := range previous[next:] {
+    token.Span = spanBetween (
+      document.textAt (token.Span.Start.Offset + delta),
+      document.textAt (token.Span.End.Offset + delta))
+    tokens = append (tokens, token)
+  }
+  document.tokens = tokens
+  return previous[next].Span.Start.Offset
+}
+
+// Edit replaces the code points from the offset start up to the offset end
+// with the replacement. The memoized results that looked at the replaced
+// code points or at the place of an insertion are forgotten, and on tokens
+// also those at the tokens that changed after the replaced code points.
+func (document *Document) Edit (start int, end int, replacement string) {
+  if start < 0 || end < start || len (document.text) < end {
+    panic (fmt.Sprintf ("parse: can't edit %d to %d in a document of " +
+      "length %d", start, end, len (document.text)))
+  }
+  var inserted = []rune (replacement)
+  var text = make ([]rune, 0, len (document.text) - (end - start) +
+                              len (inserted))
+  text = append (text, document.text[:start]...)
+  text = append (text, inserted...)
+  document.text = append (text, document.text[end:]...)
+  document.lines = &lineIndex {}
+  var delta = len (inserted) - (end - start)
+  if document.rules != nil {
+    end = document.relex (start, end, delta)
+  }
+  var entries = make (map[memoKey]memoEntry, len (document.table.entries))
+  for key, entry := range document.table.entries {
+    if entry.examined <= start {
+      entries[key] = document.shift (entry, 0)
+    } else if key.offset >= end {
+      entries[memoKey { key.parser, key.offset + delta }] =
+        document.shift (entry, delta)
+    }
+  }
+  document.table.entries = entries
+}
+
+// shift moves the memoized result by delta code points on the new text.
+func (document *Document) shift (entry memoEntry, delta int) memoEntry {
+  var result = entry.result
+  result.RemainingInput =
+    document.inputAt (offsetOf (result.RemainingInput) + delta)
+  result.Error = document.shiftError (result.Error, delta)
+  if len (result.Recovered) > 0 {
+    var recovered = make ([]*ParseError, len (result.Recovered))
+    for i, err := range result.Recovered {
+      recovered[i] = document.shiftError (err, delta)
+    }
+    result.Recovered = recovered
+  }
+  return memoEntry { result, entry.examined + delta }
+}
+
+// shiftError moves the error by delta code points on the new text.
+func (document *Document) shiftError (err *ParseError, delta int) *ParseError {
+  if err == nil {
+    return nil
+  }
+  var shifted = *err
+  shifted.Position = document.inputAt (err.Position.Offset + delta).Position ()
+  return &shifted
+}
@@ -0,0 +1,199 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "math/rand"
+  "reflect"
+  "strings"
+  "testing"
+)
+
+// testSameResults compares the results of an incremental and a full parse.
+func testSameResults (t *testing.T, text string,
+                      incremental ParserResult, full ParserResult) {
+  if !reflect.DeepEqual (incremental.Result, full.Result) ||
+     incremental.RemainingInput.Position () !=
+       full.RemainingInput.Position () ||
+     incremental.Committed != full.Committed {
+    t.Errorf ("Expected the same result as a full parse of %s!", text)
+  }
+  if (incremental.Error == nil) != (full.Error == nil) ||
+     incremental.Error != nil &&
+       incremental.Error.Error () != full.Error.Error () {
+    t.Errorf ("Expected the error %v for %s, got %v!",
+      full.Error, text, incremental.Error)
+  }
+}
+
+func TestIncrementalReparse (t *testing.T) {
+  var incrementalCalls, fullCalls int
+  var incremental = backtrackingGrammar (true, &incrementalCalls)
+  var full = backtrackingGrammar (true, &fullCalls)
+  var document = NewDocument (nestedExpression (20))
+  incremental (document.Input ())
+  var edits = []struct {
+    start, end int
+    replacement string
+  } {
+    { 60, 61, "42" },
+    { 2, 3, "-" },
+    { 30, 30, "x" },
+    { 30, 31, "" },
+    { 82, 82, "+1" },
+    { 0, 0, "(7)+" },
+  }
+  for _, edit := range edits {
+    document.Edit (edit.start, edit.end, edit.replacement)
+    incrementalCalls, fullCalls = 0, 0
+    var result = incremental (document.Input ())
+    var expected = full (Memoized (StringToInput (document.Text ())))
+    testSameResults (t, document.Text (), result, expected)
+    if incrementalCalls >= fullCalls {
+      t.Errorf ("Expected the reparse of %s to reuse results, but it " +
+        "applied Atom %d times, just like the full parse with %d!",
+        document.Text (), incrementalCalls, fullCalls)
+    }
+  }
+}
+
+func TestRandomEdits (t *testing.T) {
+  var calls int
+  var parser = backtrackingGrammar (true, &calls)
+  var random = rand.New (rand.NewSource (1))
+  var document = NewDocument (nestedExpression (5))
+  for i := 0; i < 300; i++ {
+    var length = len ([]rune (document.Text ()))
+    var start = random.Intn (length + 1)
+    var end = start + random.Intn (length - start + 1) % 3
+    var replacement = make ([]rune, random.Intn (3))
+    for j := range replacement {
+      replacement[j] = []rune ("()+-1\n")[random.Intn (6)]
+    }
+    document.Edit (start, end, string (replacement))
+    var result = parser (document.Input ())
+    var expected = parser (Memoized (StringToInput (document.Text ())))
+    testSameResults (t, document.Text (), result, expected)
+  }
+}
+
+func TestEditOutOfRange (t *testing.T) {
+  defer func () {
+    if recover () == nil {
+      t.Errorf ("Expected the edit to panic!")
+    }
+  } ()
+  NewDocument ("abc").Edit (2, 4, "")
+}
+
+// tokenGrammar is the backtrackingGrammar on tokens. It produces the texts of
+// the tokens, so that the results don't depend on where the tokens are.
+func tokenGrammar (counter *int) Parser {
+  var text = func (token interface{}) interface{} {
+    return token.(Token).Text
+  }
+  var symbol = func (symbol string) Parser {
+    return ExpectTokenText ("symbol", symbol).Convert (text)
+  }
+  var expression Parser
+  var recurse Parser = func (input ParserInput) ParserResult {
+    return expression (input)
+  }
+  var atom = symbol ("(").AndThen (recurse).AndThen (symbol (")")).
+    OrElse (ExpectToken ("number").Convert (text))
+  var countedAtom = atom
+  atom = Parser (func (input ParserInput) ParserResult {
+    *counter++
+    return countedAtom (input)
+  }).Memoize ()
+  expression = Try (atom.AndThen (symbol ("+")).AndThen (recurse)).
+    OrElse (Try (atom.AndThen (symbol ("-")).AndThen (recurse))).
+    OrElse (atom).Memoize ()
+  return expression
+}
+
+// tokenRules split the texts of the tokenGrammar and count how often they
+// look for a number.
+func tokenRules (counter *int) []LexerRule {
+  return []LexerRule {
+    SkipRule (ExpectSpaces),
+    TokenRule ("number", func (input ParserInput) ParserResult {
+      *counter++
+      return ExpectNumber (input)
+    }),
+    TokenRule ("symbol", ExpectString ("(").OrElse (ExpectString (")")).
+      OrElse (ExpectString ("+")).OrElse (ExpectString ("-"))),
+  }
+}
+
+func TestIncrementalReparseTokens (t *testing.T) {
+  var lexed int
+  var rules = tokenRules (&lexed)
+  var incrementalCalls, fullCalls int
+  var incremental = tokenGrammar (&incrementalCalls)
+  var full = tokenGrammar (&fullCalls)
+  var document = NewTokenDocument (
+    "(12 + (345 - 6)) + (78 - (9 + 10)) + 1234", rules...)
+  incremental (document.Input ())
+  var edits = []struct {
+    start, end int
+    replacement string
+  } {
+    { 7, 10, "3" },
+    { 20, 20, "  " },
+    { 38, 40, "" },
+    { 3, 3, "4" },
+    { 0, 0, "5 - " },
+    { 10, 11, "?" },
+    { 6, 7, " " },
+  }
+  for _, edit := range edits {
+    document.Edit (edit.start, edit.end, edit.replacement)
+    incrementalCalls, fullCalls = 0, 0
+    var result = incremental (document.Input ())
+    var fullDocument = NewTokenDocument (document.Text (), rules...)
+    if !reflect.DeepEqual (document.tokens, fullDocument.tokens) {
+      t.Errorf ("Expected the tokens %v for %s, got %v!",
+        fullDocument.tokens, document.Text (), document.tokens)
+    }
+    testSameResults (t, document.Text (), result, full (fullDocument.Input ()))
+    if incrementalCalls >= fullCalls {
+      t.Errorf ("Expected the reparse of %s to reuse results, but it " +
+        "applied Atom %d times, just like the full parse with %d!",
+        document.Text (), incrementalCalls, fullCalls)
+    }
+  }
+}
+
+func TestTokenDocumentRelexesAroundEdits (t *testing.T) {
+  var lexed int
+  var text = strings.Repeat ("(1 + 23) - ", 1000) + "4"
+  var document = NewTokenDocument (text, tokenRules (&lexed)...)
+  lexed = 0
+  document.Edit (5005, 5006, "4 + 5")
+  if lexed > 10 {
+    t.Errorf ("Expected the edit to split only a few tokens again, but " +
+      "the rules looked for %d numbers!", lexed)
+  }
+  if !reflect.DeepEqual (document.tokens,
+       NewTokenDocument (document.Text (), tokenRules (&lexed)...).tokens) {
+    t.Errorf ("Expected the same tokens as in a new document!")
+  }
+}
@@ -0,0 +1,89 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "sync/atomic"
+)
+
+// LeftRecursive lets you write left-recursive rules like
+//
+//   Expression := Expression "-" Number | Number
+//
+// as parsers. The definition receives the parser itself as its argument so
+// that it can refer to itself, even in the leftmost position:
+//
+//   var Expression = LeftRecursive (func (expression Parser) Parser {
+//     return expression.AndThen (ExpectString ("-")).AndThen (ExpectNumber).
+//       OrElse (ExpectNumber)
+//   })
+//
+// The result is left-associative, e.g. "1-2-3" becomes ((1 - 2) - 3).
+// LeftRecursive grows the result like a seed: first the recursive reference
+// fails, so only the non-recursive alternatives can succeed. Then the
+// recursive reference produces the previous result, and so on, as long as
+// the parser consumes more input than before.
+//
+// The recursion may also be indirect. Just don't memoize the parsers that
+// lead back to the LeftRecursive parser without consuming any input because
+// they would remember an unfinished seed. The LeftRecursive parser itself and
+// all other parsers may be memoized.
+//
+// The seeds are kept in the memo table of the input, so the parser may be
+// used by several goroutines at once. If the input isn't a MemoInput then
+// the parser wraps it into one while it runs, which also activates the
+// memoized parsers inside of its definition.
+func LeftRecursive (definition func (Parser) Parser) Parser {
+  var identity = atomic.AddUint64 (&lastMemoizedParser, 1)
+  var body Parser
+  var parser Parser
+  parser = func (input ParserInput) ParserResult {
+    var memoInput, isMemoInput = input.(MemoInput)
+    if !isMemoInput {
+      var result = parser (Memoized (input))
+      result.RemainingInput = underlying (result.RemainingInput)
+      return result
+    }
+    var seeds = memoInput.table.seeds
+    var key = memoKey { identity, memoInput.offset }
+    if seed, isGrowing := seeds[key]; isGrowing {
+      return seed
+    }
+    defer delete (seeds, key)
+    var seed = ParserResult { RemainingInput: input }
+    for {
+      seeds[key] = seed
+      var result = body (input)
+      if result.Result == nil || seed.Result != nil &&
+         offsetOf (result.RemainingInput) <= offsetOf (seed.RemainingInput) {
+        if seed.Result == nil {
+          seed = result
+        } else {
+          seed.Error = mergeErrors (seed.Error, result.Error)
+        }
+        break
+      }
+      seed = result
+    }
+    return seed
+  }
+  body = definition (parser)
+  return parser
+}
@@ -0,0 +1,177 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "strings"
+  "sync"
+  "testing"
+)
+
+// bracket turns a Pair of a Pair into "(first op second)"
+func bracket (result interface{}) interface{} {
+  var pair = result.(Pair)
+  var operation = pair.First.(Pair)
+  return "(" + operation.First.(string) + operation.Second.(string) +
+    pair.Second.(string) + ")"
+}
+
+// leftRecursiveArithmetic parses
+//
+//   Expression := Expression ("+" | "-") Term | Term
+//   Term       := Term "*" Factor | Factor
+//   Factor     := Number | "(" Expression ")"
+//
+// The memoized version memoizes every rule.
+func leftRecursiveArithmetic (memoize bool) Parser {
+  var expression Parser
+  var factor = ExpectNumber.OrElse (ExpectString ("(").
+    AndThen (func (input ParserInput) ParserResult {
+      return expression (input)
+    }).AndThen (ExpectString (")")).First ().Second ())
+  if memoize {
+    factor = factor.Memoize ()
+  }
+  var term = LeftRecursive (func (term Parser) Parser {
+    return term.AndThen (ExpectString ("*")).AndThen (factor).
+      Convert (bracket).OrElse (factor)
+  })
+  if memoize {
+    term = term.Memoize ()
+  }
+  expression = LeftRecursive (func (expression Parser) Parser {
+    return expression.AndThen (ExpectString ("+").OrElse (ExpectString ("-"))).
+      AndThen (term).Convert (bracket).OrElse (term)
+  })
+  if memoize {
+    expression = expression.Memoize ()
+  }
+  return expression
+}
+
+func TestLeftRecursive (t *testing.T) {
+  var parser = leftRecursiveArithmetic (false)
+  var tests = map[string] string {
+    "1": "1",
+    "1-2-3": "((1-2)-3)",
+    "1+2*3*4-5": "((1+((2*3)*4))-5)",
+    "(1-2)*(3-(4-5))-6": "(((1-2)*(3-(4-5)))-6)",
+  }
+  for text, expected := range tests {
+    for _, input := range []ParserInput {
+        StringToInput (text), FileToInput (strings.NewReader (text)) } {
+      var result = parser (input)
+      if result.Result != expected || !result.RemainingInput.AtEnd () {
+        t.Errorf ("Expected %s to become %s, got %v!",
+          text, expected, result.Result)
+      }
+    }
+  }
+}
+
+func TestLeftRecursiveErrors (t *testing.T) {
+  var parser = leftRecursiveArithmetic (false)
+  var result = parser (StringToInput ("1+2x"))
+  if result.Result != "(1+2)" ||
+     result.RemainingInput.CurrentCodePoint () != 'x' {
+    t.Errorf ("Expected the parser to stop before the x, got %v!",
+      result.Result)
+  }
+  testErrorMessage (t, result, "expected '*', '+' or '-' at 1:4, found 'x'")
+  result = parser (StringToInput ("(1+"))
+  testErrorMessage (t, result, "expected number or '(' at end of input")
+}
+
+func TestMemoizedLeftRecursive (t *testing.T) {
+  var parser = leftRecursiveArithmetic (true)
+  var text = strings.Repeat ("(1-", 100) + "1" + strings.Repeat (")", 100)
+  var result = parser (Memoized (StringToInput (text)))
+  if result.Result == nil || !result.RemainingInput.AtEnd () {
+    t.Errorf ("Expected the memoized parser to parse the whole input!")
+  }
+}
+
+func TestLeftRecursiveConcurrently (t *testing.T) {
+  var parser = leftRecursiveArithmetic (false)
+  var group sync.WaitGroup
+  for i := 0; i < 8; i++ {
+    group.Add (1)
+    go func () {
+      defer group.Done ()
+      for j := 0; j < 50; j++ {
+        var result = parser (StringToInput ("1-2-3-4-5-6"))
+        if result.Result != "(((((1-2)-3)-4)-5)-6)" {
+          t.Errorf ("Expected every goroutine to get the whole result, " +
+            "got %v and %v!", result.Result, result.Error)
+          return
+        }
+      }
+    } ()
+  }
+  group.Wait ()
+}
+
+func TestLeftRecursivePanic (t *testing.T) {
+  var panics = true
+  var parser = LeftRecursive (func (expression Parser) Parser {
+    return expression.AndThen (ExpectString ("-")).AndThen (ExpectNumber).
+      Convert (bracket).OrElse (func (input ParserInput) ParserResult {
+        if panics {
+          panic ("number")
+        }
+        return ExpectNumber (input)
+      })
+  })
+  var input = Memoized (StringToInput ("1-2"))
+  func () {
+    defer func () {
+      recover ()
+    } ()
+    parser (input)
+  } ()
+  panics = false
+  if result := parser (input); result.Result != "(1-2)" {
+    t.Errorf ("Expected the panic not to leave a seed behind, got %v!",
+      result.Result)
+  }
+}
+
+func TestLeftRecursiveTokens (t *testing.T) {
+  var lexer = Lexer (SkipRule (ExpectSpaces),
+    TokenRule ("number", ExpectNumber),
+    TokenRule ("operator", ExpectString ("-")))
+  var number = ExpectToken ("number").
+    Convert (func (token interface{}) interface{} {
+      return token.(Token).Text
+    })
+  var parser = LeftRecursive (func (expression Parser) Parser {
+    return expression.AndThen (ExpectTokenText ("operator", "-").
+      Convert (func (token interface{}) interface{} {
+        return token.(Token).Text
+      })).AndThen (number).Convert (bracket).OrElse (number)
+  })
+  var tokens = lexer (StringToInput ("1 - 2 - 3")).Result.([]Token)
+  var result = parser (TokensToInput (tokens))
+  if _, isTokenInput := result.RemainingInput.(TokenInput);
+     result.Result != "((1-2)-3)" || !isTokenInput {
+    t.Errorf ("Expected the tokens to become ((1-2)-3), got %v and %v!",
+      result.Result, result.Error)
+  }
+}
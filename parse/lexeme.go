@@ -0,0 +1,224 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+// blockComment describes comments like /* ... */.
+type blockComment struct {
+  open string
+  close string
+  nestable bool
+}
+
+// Lexeme describes the trivia of a language, i.e. the spaces and comments
+// that may come before every token, and derives parsers for tokens that skip
+// the trivia before them:
+//
+//   var lexeme = NewLexeme ().LineComment ("//").
+//     BlockComment ("/*", "*/", true).Keywords ("if", "else")
+//   var condition = lexeme.Keyword ("if").AndThen (lexeme.Symbol ("(")).
+//     AndThen (lexeme.Identifier ()).AndThen (lexeme.Symbol (")"))
+//
+// Like MaybeSpacesBefore, the derived parsers don't count the trivia as
+// consumed input. Declaring more trivia or keywords afterwards doesn't change
+// the parsers that have already been derived.
+type Lexeme struct {
+  isSpace func (rune) bool
+  lineComments []string
+  blockComments []blockComment
+  keywords []string
+}
+
+// NewLexeme creates a configuration whose only trivia are the spaces
+// [ \t\n\r].
+func NewLexeme () *Lexeme {
+  return &Lexeme { isSpaceChar, nil, nil, nil }
+}
+
+// Spaces replaces the space characters, e.g. with unicode.IsSpace.
+func (lexeme *Lexeme) Spaces (isSpace func (rune) bool) *Lexeme {
+  lexeme.isSpace = isSpace
+  return lexeme
+}
+
+// LineComment declares comments that start with the text, e.g. "//" or "#",
+// and end before the next line break. It panics if the text is empty.
+func (lexeme *Lexeme) LineComment (start string) *Lexeme {
+  if start == "" {
+    panic ("parse: a line comment needs a start")
+  }
+  lexeme.lineComments = append (lexeme.lineComments, start)
+  return lexeme
+}
+
+// BlockComment declares comments between the texts open and close, e.g.
+// "/*" and "*/". If the comments are nestable then every open inside of
+// a comment needs a close of its own. It panics if open or close is empty.
+func (lexeme *Lexeme) BlockComment (open string, close string,
+                                    nestable bool) *Lexeme {
+  if open == "" || close == "" {
+    panic ("parse: a block comment needs an open and a close")
+  }
+  lexeme.blockComments = append (lexeme.blockComments,
+    blockComment { open, close, nestable })
+  return lexeme
+}
+
+// Keywords declares words that Identifier doesn't accept.
+func (lexeme *Lexeme) Keywords (words ...string) *Lexeme {
+  lexeme.keywords = append (lexeme.keywords, words...)
+  return lexeme
+}
+
+// Trivia parses any number of spaces and comments, possibly none, and the
+// result will be their text. It fails with a committed error if a block
+// comment isn't closed. Use it at the end of your grammar to allow trivia
+// after the last token.
+func (lexeme *Lexeme) Trivia () Parser {
+  var isSpace = lexeme.isSpace
+  var lineComments = append ([]string {}, lexeme.lineComments...)
+  var blockComments = append ([]blockComment {}, lexeme.blockComments...)
+  return func (input ParserInput) ParserResult {
+    var remaining = input
+    for !remaining.AtEnd () {
+      if isSpace (remaining.CurrentCodePoint ()) {
+        remaining = remaining.RemainingInput ()
+      } else if next, isComment := skipLineComment (remaining,
+                                                    lineComments); isComment {
+        remaining = next
+      } else if next, isComment, err := skipBlockComment (remaining,
+                                          blockComments); err != nil {
+        return ParserResult {
+          RemainingInput: input, Error: err, Committed: true }
+      } else if isComment {
+        remaining = next
+      } else {
+        break
+      }
+    }
+    return ParserResult {
+      Result: textBetween (input, remaining), RemainingInput: remaining }
+  }
+}
+
+// skipLineComment returns the input before the line break after a comment
+// if the input starts with one of the comments.
+func skipLineComment (input ParserInput,
+                      comments []string) (ParserInput, bool) {
+  for _, start := range comments {
+    if remaining, isComment := skipText (input, start); isComment {
+      for !remaining.AtEnd () && remaining.CurrentCodePoint () != '\n' {
+        remaining = remaining.RemainingInput ()
+      }
+      return remaining, true
+    }
+  }
+  return input, false
+}
+
+// skipBlockComment returns the input after a comment if the input starts
+// with one of the comments. The error tells which close is missing.
+func skipBlockComment (input ParserInput, comments []blockComment) (
+                         ParserInput, bool, *ParseError) {
+  for _, comment := range comments {
+    var remaining, isComment = skipText (input, comment.open)
+    if !isComment {
+      continue
+    }
+    for depth := 1; depth > 0; {
+      if next, isClose := skipText (remaining, comment.close); isClose {
+        remaining = next
+        depth--
+      } else if next, isOpen := skipText (remaining,
+                                          comment.open); isOpen &&
+                comment.nestable {
+        remaining = next
+        depth++
+      } else if remaining.AtEnd () {
+        return input, true,
+          newParseError (remaining, 1, []string { quote (comment.close) })
+      } else {
+        remaining = remaining.RemainingInput ()
+      }
+    }
+    return remaining, true, nil
+  }
+  return input, false, nil
+}
+
+// TriviaBefore allows and ignores trivia before applying the parser. Just
+// like with MaybeSpacesBefore, the trivia don't count as consumed input.
+func (lexeme *Lexeme) TriviaBefore (parser Parser) Parser {
+  var trivia = lexeme.Trivia ()
+  return func (input ParserInput) ParserResult {
+    var skipped = trivia (input)
+    if skipped.Result == nil {
+      return skipped
+    }
+    var result = parser (skipped.RemainingInput)
+    if result.Result == nil {
+      result.RemainingInput = input
+    }
+    return result
+  }
+}
+
+// Symbol parses the text after the trivia, e.g. an operator or
+// a parenthesis.
+func (lexeme *Lexeme) Symbol (text string) Parser {
+  return lexeme.TriviaBefore (ExpectString (text))
+}
+
+// Keyword parses the word after the trivia unless it's just the beginning of
+// a longer identifier: Keyword ("if") doesn't accept "iffy".
+func (lexeme *Lexeme) Keyword (word string) Parser {
+  var expectWord = ExpectString (word)
+  return lexeme.TriviaBefore (func (input ParserInput) ParserResult {
+    var result = expectWord (input)
+    if result.Result != nil && !result.RemainingInput.AtEnd () &&
+       isIdentifierChar (result.RemainingInput.CurrentCodePoint ()) {
+      return Failure (input, quote (word))
+    }
+    return result
+  })
+}
+
+// Identifier parses an identifier like ExpectIdentifier after the trivia,
+// except for the keywords.
+func (lexeme *Lexeme) Identifier () Parser {
+  var keywords = make (map[string]bool, len (lexeme.keywords))
+  for _, keyword := range lexeme.keywords {
+    keywords[keyword] = true
+  }
+  return lexeme.TriviaBefore (func (input ParserInput) ParserResult {
+    var result = ExpectIdentifier (input)
+    if word, isWord := result.Result.(string); isWord && keywords[word] {
+      return ParserResult {
+        RemainingInput: input,
+        Error: newParseError (input, len ([]rune (word)),
+                              []string { "identifier" }) }
+    }
+    return result
+  })
+}
+
+// Number parses a number like ExpectNumber after the trivia.
+func (lexeme *Lexeme) Number () Parser {
+  return lexeme.TriviaBefore (ExpectNumber)
+}
@@ -0,0 +1,135 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "container/list"
+  "strings"
+  "testing"
+  "unicode"
+)
+
+// testLexeme has C-like comments and the keywords of a tiny language.
+var testLexeme = NewLexeme ().LineComment ("//").LineComment ("#").
+  BlockComment ("/*", "*/", true).Keywords ("if", "else")
+
+func TestTrivia (t *testing.T) {
+  var tests = []struct {
+    input string
+    trivia string
+  } {
+    { "x", "" },
+    { "  \n\tx", "  \n\t" },
+    { "// line\n# hash\nx", "// line\n# hash\n" },
+    { "/* a /* nested */ b */x", "/* a /* nested */ b */" },
+    { "/**/ // end", "/**/ // end" },
+    { "/ x", "" },
+  }
+  for _, test := range tests {
+    var result = testLexeme.Trivia () (StringToInput (test.input))
+    if result.Result != test.trivia {
+      t.Errorf ("Expected the trivia %q in %q, got %q and %v!",
+        test.trivia, test.input, result.Result, result.Error)
+    }
+  }
+}
+
+func TestUnclosedBlockComment (t *testing.T) {
+  var result = testLexeme.Symbol ("x") (StringToInput ("/* a /* b */ x"))
+  if result.Result != nil || !result.Committed || result.Error == nil ||
+     result.Error.Error () != "expected '*/' at end of input" {
+    t.Errorf ("Expected the unclosed comment to be an error, got %v!",
+      result.Error)
+  }
+  var flat = NewLexeme ().BlockComment ("(*", "*)", false).Symbol ("x")
+  if flat (StringToInput ("(* (* *) x")).Result != "x" {
+    t.Errorf ("Expected comments that aren't nestable to end at the " +
+      "first close!")
+  }
+}
+
+func TestEmptyCommentDelimiters (t *testing.T) {
+  for name, declare := range map[string]func () {
+      "line comment": func () { NewLexeme ().LineComment ("") },
+      "open": func () { NewLexeme ().BlockComment ("", "*/", false) },
+      "close": func () { NewLexeme ().BlockComment ("/*", "", true) } } {
+    func () {
+      defer func () {
+        if recover () == nil {
+          t.Errorf ("Expected an empty %s to panic!", name)
+        }
+      } ()
+      declare ()
+    } ()
+  }
+}
+
+func TestLexemeTokens (t *testing.T) {
+  var statement = Sequence (testLexeme.Keyword ("if"), testLexeme.Symbol ("("),
+    testLexeme.Identifier (), testLexeme.Symbol (")"), testLexeme.Number ()).
+    AndThen (testLexeme.Trivia ()).First ()
+  var text = "if /* test */ (iffy) // then\n  42 # done"
+  for _, input := range []ParserInput { StringToInput (text),
+                                        FileToInput (strings.NewReader (text)) } {
+    var result = statement.ParseAll (input)
+    var values, isSlice = result.Result.([]interface{})
+    if !isSlice || values[2] != "iffy" || values[4] != "42" {
+      t.Errorf ("Expected %s to be parsed, got %v and %v!",
+        text, result.Result, result.Error)
+    }
+  }
+}
+
+func TestLexemeErrors (t *testing.T) {
+  var tests = []struct {
+    parser Parser
+    input string
+    expected string
+  } {
+    { testLexeme.Keyword ("if"), " iffy", "expected 'if' at 1:2, found 'i'" },
+    { testLexeme.Identifier (), "/**/else", "expected identifier at 1:5, " +
+      "found 'else'" },
+    { testLexeme.Symbol ("("), "# x\n)", "expected '(' at 2:1, found ')'" },
+    { testLexeme.Number (), "x", "expected number at 1:1, found 'x'" },
+  }
+  for _, test := range tests {
+    var result = test.parser (StringToInput (test.input))
+    if result.Result != nil || result.Committed ||
+       offsetOf (result.RemainingInput) != 0 || result.Error == nil ||
+       result.Error.Error () != test.expected {
+      t.Errorf ("Expected the error %s for %s, got %v and %v!",
+        test.expected, test.input, result.Result, result.Error)
+    }
+  }
+}
+
+func TestLexemeConfiguration (t *testing.T) {
+  var lexeme = NewLexeme ()
+  var identifiers = lexeme.Identifier ().Repeated ()
+  lexeme.Spaces (unicode.IsSpace).Keywords ("a")
+  var result = identifiers (StringToInput ("a b\u00a0c"))
+  if result.Result.(*list.List).Len () != 2 {
+    t.Errorf ("Expected later configuration not to change the parser!")
+  }
+  result = lexeme.Identifier ().Repeated () (StringToInput ("b\u00a0c a"))
+  if result.Result.(*list.List).Len () != 2 {
+    t.Errorf ("Expected the new spaces and keywords to be used!")
+  }
+}
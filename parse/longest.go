@@ -0,0 +1,55 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+// Longest applies all the parsers to the same input and produces the result
+// of the one that consumed the most input, like the maximal munch of
+// a lexer: Longest (ExpectString ("<"), ExpectString ("<="))
+// parses all of "<=". If several parsers consume the same amount of input
+// then the first of them wins, so Longest (keyword, ExpectIdentifier) parses
+// "if" as a keyword and "iffy" as an identifier. Unlike OrElse, Longest tries
+// all parsers even if one of them fails after committing. If all of them
+// fail then the errors are merged and the failure is committed if any of the
+// failures is.
+func Longest (parsers ...Parser) Parser {
+  return func (input ParserInput) ParserResult {
+    var longest = Failure (input)
+    var errors *ParseError
+    var committed = false
+    for _, parser := range parsers {
+      var result = parser (input)
+      errors = mergeErrors (errors, result.Error)
+      if result.Result == nil {
+        committed = committed || result.Committed
+      } else if longest.Result == nil ||
+                offsetOf (result.RemainingInput) >
+                  offsetOf (longest.RemainingInput) {
+        longest = result
+      }
+    }
+    if longest.Result == nil {
+      longest.Committed = committed
+    }
+    if len (parsers) > 0 {
+      longest.Error = errors
+    }
+    return longest
+  }
+}
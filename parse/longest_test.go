@@ -0,0 +1,49 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "testing"
+)
+
+func TestLongest (t *testing.T) {
+  var keyword = ExpectString ("if").Convert (func (interface{}) interface{} {
+    return "keyword"
+  })
+  var comparison = Longest (ExpectString ("<"), ExpectString ("<="),
+                            ExpectString ("<<"), ExpectString ("<<="))
+  var tests = []parserTest {
+    { comparison, "<=x", "<=", "x", false },
+    { comparison, "<<=", "<<=", "", false },
+    { comparison, "< =", "<", " =", false },
+    { comparison, "x", "expected '<', '<=', '<<' or '<<=' at 1:1, found 'x'",
+      "x", false },
+    { Longest (keyword, ExpectIdentifier), "if x", "keyword", " x", false },
+    { Longest (keyword, ExpectIdentifier), "iffy", "iffy", "", false },
+    { Longest (ExpectIdentifier, keyword), "if", "if", "", false },
+    { Longest (ExpectString ("ab").AndThen (ExpectString ("c")),
+               ExpectString ("a")), "abd", "a", "bd", false },
+    { Longest (ExpectString ("ab").AndThen (ExpectString ("c")),
+               ExpectString ("x")), "abd",
+      "expected 'c' at 1:3, found 'd'", "abd", true },
+    { Longest (), "a", "unexpected 'a' at 1:1", "a", false },
+  }
+  testParsers (t, tests)
+}
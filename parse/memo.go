@@ -0,0 +1,172 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "sync/atomic"
+)
+
+// memoKey identifies the result of one memoized parser at one position. All
+// offsets in a memo table count code points of the source text, even when the
+// input consists of tokens.
+type memoKey struct {
+  parser uint64
+  offset int
+}
+
+// memoEntry is the result of one memoized parser at one position along with
+// the extent of the input that the parser looked at to produce the result.
+type memoEntry struct {
+  result ParserResult
+
+  // examined is the offset right after the last code point that the parser
+  // looked at.
+  examined int
+}
+
+// memoTable stores the results of the memoized parsers at every position
+// of one input.
+type memoTable struct {
+  entries map[memoKey]memoEntry
+
+  // examined is the offset right after the last code point that the
+  // running memoized parsers have looked at so far.
+  examined int
+
+  // seeds are the results that the running LeftRecursive parsers have grown
+  // so far at their offsets.
+  seeds map[memoKey]ParserResult
+}
+
+// newMemoTable creates an empty memo table.
+func newMemoTable () *memoTable {
+  return &memoTable { make (map[memoKey]memoEntry), 0,
+                      make (map[memoKey]ParserResult) }
+}
+
+// examine records that the running parsers looked at the code points
+// before the offset.
+func (table *memoTable) examine (offset int) {
+  if offset > table.examined {
+    table.examined = offset
+  }
+}
+
+// lastMemoizedParser is the identity of the most recently memoized parser.
+var lastMemoizedParser uint64
+
+// MemoInput is an implementation of ParserInput that carries a memo table
+// along with another input. Use Memoized to create instances of this type.
+// All inputs that remain after parsing a MemoInput share the same table.
+type MemoInput struct {
+
+  // Input is the underlying input.
+  Input ParserInput
+
+  // table is shared by all positions of the same input
+  table *memoTable
+
+  // offset is the offset of the Input
+  offset int
+}
+
+// examineCurrent records that the running parsers looked at the current code
+// point of the input. Looking at a token means looking at its text and at
+// the code point after it, which decided where the token ends.
+func (input MemoInput) examineCurrent () {
+  if tokens, isTokenInput := input.Input.(TokenInput);
+     isTokenInput && !tokens.AtEnd () {
+    input.table.examine (tokens.CurrentToken ().Span.End.Offset + 1)
+  } else {
+    input.table.examine (input.offset + 1)
+  }
+}
+
+// underlying returns the input that a MemoInput wraps and any other input
+// as it is, so that parsers for special inputs like TokenInput also work on
+// memoized inputs.
+func underlying (input ParserInput) ParserInput {
+  if memoInput, isMemoInput := input.(MemoInput); isMemoInput {
+    return underlying (memoInput.Input)
+  }
+  return input
+}
+
+// Memoized wraps the input into a MemoInput so that parsers created with
+// Memoize remember their results.
+func Memoized (input ParserInput) ParserInput {
+  return MemoInput { input, newMemoTable (), offsetOf (input) }
+}
+
+// CurrentCodePoint is necessary for MemoInput to implement ParserInput
+func (input MemoInput) CurrentCodePoint () rune {
+  input.examineCurrent ()
+  return input.Input.CurrentCodePoint ()
+}
+
+// RemainingInput is necessary for MemoInput to implement ParserInput
+func (input MemoInput) RemainingInput () ParserInput {
+  if input.Input.AtEnd () {
+    return input
+  }
+  var remainingInput = input.Input.RemainingInput ()
+  return MemoInput { remainingInput, input.table, offsetOf (remainingInput) }
+}
+
+// Position is necessary for MemoInput to implement ParserInput
+func (input MemoInput) Position () Position {
+  return input.Input.Position ()
+}
+
+// AtEnd is necessary for MemoInput to implement ParserInput
+func (input MemoInput) AtEnd () bool {
+  input.examineCurrent ()
+  return input.Input.AtEnd ()
+}
+
+// Memoize makes the parser remember its result at every position of
+// a MemoInput, so that it doesn't parse the same part of the input twice.
+// This is also called packrat parsing and makes grammars with lots of
+// back-tracking alternatives run in linear time. On other inputs the parser
+// works as usual. Every call of Memoize creates a new parser with a memory
+// of its own, so don't call it every time you apply the parser! Define your
+// memoized parsers once, e.g. as package-level variables.
+func (parser Parser) Memoize () Parser {
+  var identity = atomic.AddUint64 (&lastMemoizedParser, 1)
+  return func (input ParserInput) ParserResult {
+    var memoInput, isMemoInput = input.(MemoInput)
+    if !isMemoInput {
+      return parser (input)
+    }
+    var table = memoInput.table
+    var key = memoKey { identity, memoInput.offset }
+    if entry, isKnown := table.entries[key]; isKnown {
+      table.examine (entry.examined)
+      return entry.result
+    }
+    var examinedBefore = table.examined
+    table.examined = key.offset
+    var result = parser (input)
+    table.examine (offsetOf (result.RemainingInput))
+    table.entries[key] = memoEntry { result, table.examined }
+    table.examine (examinedBefore)
+    return result
+  }
+}
@@ -0,0 +1,134 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "reflect"
+  "strings"
+  "testing"
+)
+
+// backtrackingGrammar creates a parser for the grammar
+//
+//   Expression := Atom "+" Expression | Atom "-" Expression | Atom
+//   Atom       := "(" Expression ")" | Number
+//
+// which parses every Atom three times. Nested parentheses make it
+// exponentially slow unless it's memoized. Every application of Atom
+// increments the counter.
+func backtrackingGrammar (memoize bool, counter *int) Parser {
+  var expression Parser
+  var recurse Parser = func (input ParserInput) ParserResult {
+    return expression (input)
+  }
+  var atom = ExpectString ("(").AndThen (recurse).AndThen (ExpectString (")")).
+    OrElse (ExpectNumber)
+  var countedAtom = atom
+  atom = func (input ParserInput) ParserResult {
+    *counter++
+    return countedAtom (input)
+  }
+  if memoize {
+    atom = atom.Memoize ()
+  }
+  expression = Try (atom.AndThen (ExpectString ("+")).AndThen (recurse)).
+    OrElse (Try (atom.AndThen (ExpectString ("-")).AndThen (recurse))).
+    OrElse (atom)
+  if memoize {
+    expression = expression.Memoize ()
+  }
+  return expression
+}
+
+func nestedExpression (depth int) string {
+  return strings.Repeat ("(1+", depth) + "1" + strings.Repeat (")", depth)
+}
+
+func TestMemoize (t *testing.T) {
+  var text = nestedExpression (6)
+  var plainCalls, memoCalls int
+  var plain = backtrackingGrammar (false, &plainCalls) (StringToInput (text))
+  var memo = backtrackingGrammar (true, &memoCalls) (
+    Memoized (StringToInput (text)))
+  if plain.Result == nil || !reflect.DeepEqual (plain.Result, memo.Result) {
+    t.Errorf ("Expected the memoized parser to produce the same result!")
+  }
+  if !plain.RemainingInput.AtEnd () || !memo.RemainingInput.AtEnd () {
+    t.Errorf ("Expected both parsers to parse the whole input!")
+  }
+  if memoCalls != 13 {
+    t.Errorf ("Expected 13 applications of Atom, one per position " +
+      "where an Atom can start, got %d!", memoCalls)
+  }
+  if plainCalls <= 3 * 3 * 3 * 3 * 3 * 3 {
+    t.Errorf ("Expected exponentially many applications of Atom " +
+      "without memoization, got %d!", plainCalls)
+  }
+}
+
+func TestMemoizeWithoutMemoInput (t *testing.T) {
+  var plainCalls, memoCalls int
+  backtrackingGrammar (false, &plainCalls) (StringToInput ("(1-1)"))
+  var result = backtrackingGrammar (true, &memoCalls) (StringToInput ("(1-1)"))
+  if result.Result == nil || memoCalls != plainCalls {
+    t.Errorf ("Expected the memoized parser to work as usual without " +
+      "memo table, but it applied Atom %d instead of %d times!",
+      memoCalls, plainCalls)
+  }
+}
+
+func benchmarkBacktracking (b *testing.B, memoize bool) {
+  var calls int
+  var parser = backtrackingGrammar (memoize, &calls)
+  var text = nestedExpression (7)
+  for i := 0; i < b.N; i++ {
+    var input = StringToInput (text)
+    if memoize {
+      input = Memoized (input)
+    }
+    if parser (input).Result == nil {
+      b.Fatalf ("Expected the parser to succeed!")
+    }
+  }
+}
+
+func BenchmarkNestedWithoutMemoization (b *testing.B) {
+  benchmarkBacktracking (b, false)
+}
+
+func BenchmarkNestedWithMemoization (b *testing.B) {
+  benchmarkBacktracking (b, true)
+}
+
+func TestMemoizeCountsCodePointsOnTokens (t *testing.T) {
+  var tokens = lex (t, "x = 42;")
+  var identifier = ExpectToken ("identifier").Memoize ()
+  var number = ExpectToken ("number").Memoize ()
+  var input = Memoized (tokens).(MemoInput)
+  identifier.AndThen (ExpectToken ("symbol")).AndThen (number) (input)
+  var offsets = make (map[int]int)
+  for key, entry := range input.table.entries {
+    offsets[key.offset] = entry.examined
+  }
+  if len (offsets) != 2 || offsets[0] < 1 || offsets[4] < 6 {
+    t.Errorf ("Expected the results of x at 0 and 42 at 4 after looking " +
+      "at their code points, got the offsets %v!", offsets)
+  }
+}
@@ -0,0 +1,255 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "math/big"
+  "strconv"
+  "strings"
+)
+
+// numberLiteral is a number literal that has been read from the input.
+type numberLiteral struct {
+  negative bool
+  base int
+
+  // digits are the digits before the fraction without the underscores.
+  digits string
+
+  // fraction are the digits after the decimal point.
+  fraction string
+
+  // exponent is the decimal exponent with its sign.
+  exponent string
+
+  // width is the number of code points of the literal.
+  width int
+
+  remainingInput ParserInput
+}
+
+// sign is "-" for negative literals and "" otherwise.
+func (literal numberLiteral) sign () string {
+  if literal.negative {
+    return "-"
+  }
+  return ""
+}
+
+// digitValue is the value of a hexadecimal digit or 16 for other code points.
+func digitValue (codePoint rune) int {
+  switch {
+  case '0' <= codePoint && codePoint <= '9':
+    return int (codePoint - '0')
+  case 'a' <= codePoint && codePoint <= 'f':
+    return int (codePoint - 'a') + 10
+  case 'A' <= codePoint && codePoint <= 'F':
+    return int (codePoint - 'A') + 10
+  }
+  return 16
+}
+
+// isDigitOf is true iff the input starts with a digit of the base.
+func isDigitOf (input ParserInput, base int) bool {
+  return !input.AtEnd () && digitValue (input.CurrentCodePoint ()) < base
+}
+
+// scanDigits reads the digits of the base at the beginning of the literal.
+// Single underscores may separate the digits and, after a radix prefix, come
+// before the first digit.
+func (literal *numberLiteral) scanDigits (base int,
+                                          afterPrefix bool) string {
+  var builder strings.Builder
+  var input = literal.remainingInput
+  for {
+    if isDigitOf (input, base) {
+      builder.WriteRune (input.CurrentCodePoint ())
+    } else if !input.AtEnd () && input.CurrentCodePoint () == '_' &&
+              (builder.Len () > 0 || afterPrefix) &&
+              isDigitOf (input.RemainingInput (), base) {
+      literal.width++
+      input = input.RemainingInput ()
+      continue
+    } else {
+      break
+    }
+    literal.width++
+    input = input.RemainingInput ()
+  }
+  literal.remainingInput = input
+  return builder.String ()
+}
+
+// skip consumes the first code point of the literal if it's one of the
+// codePoints and returns it.
+func (literal *numberLiteral) skip (codePoints string) (rune, bool) {
+  var input = literal.remainingInput
+  if input.AtEnd () ||
+     !strings.ContainsRune (codePoints, input.CurrentCodePoint ()) {
+    return 0, false
+  }
+  literal.width++
+  literal.remainingInput = input.RemainingInput ()
+  return input.CurrentCodePoint (), true
+}
+
+// scanSign reads an optional sign out of the signs.
+func (literal *numberLiteral) scanSign (signs string) {
+  var sign, hasSign = literal.skip (signs)
+  literal.negative = hasSign && sign == '-'
+}
+
+// radixPrefixes are the bases of the letters after the 0 of a radix prefix.
+var radixPrefixes = map[rune]int {
+  'x': 16, 'X': 16, 'o': 8, 'O': 8, 'b': 2, 'B': 2 }
+
+// scanInteger reads an integer literal with one of the signs. It's false if
+// there are no digits.
+func scanInteger (input ParserInput, signs string) (numberLiteral, bool) {
+  var literal = numberLiteral { base: 10, remainingInput: input }
+  literal.scanSign (signs)
+  var afterSign = literal
+  if _, isZero := literal.skip ("0"); isZero {
+    if prefix, hasPrefix := literal.skip ("xXoObB"); hasPrefix {
+      literal.base = radixPrefixes[prefix]
+      literal.digits = literal.scanDigits (literal.base, true)
+      if literal.digits != "" {
+        return literal, true
+      }
+    }
+    literal = afterSign
+  }
+  literal.digits = literal.scanDigits (10, false)
+  return literal, literal.digits != ""
+}
+
+// scanFloat reads a decimal floating-point literal with an optional sign. It's
+// false if there are no digits.
+func scanFloat (input ParserInput) (numberLiteral, bool) {
+  var literal = numberLiteral { base: 10, remainingInput: input }
+  literal.scanSign ("+-")
+  literal.digits = literal.scanDigits (10, false)
+  var beforeFraction = literal
+  if _, hasPoint := literal.skip ("."); hasPoint {
+    literal.fraction = literal.scanDigits (10, false)
+    if literal.fraction == "" {
+      literal = beforeFraction
+    }
+  }
+  if literal.digits == "" && literal.fraction == "" {
+    return literal, false
+  }
+  var beforeExponent = literal
+  if _, hasExponent := literal.skip ("eE"); hasExponent {
+    var sign, _ = literal.skip ("+-")
+    literal.exponent = literal.scanDigits (10, false)
+    if literal.exponent == "" {
+      literal = beforeExponent
+    } else if sign == '-' {
+      literal.exponent = "-" + literal.exponent
+    }
+  }
+  return literal, true
+}
+
+// expectNumberLiteral scans a literal and converts it. It expects the
+// description if there's no literal and the rangeDescription if the
+// conversion fails.
+func expectNumberLiteral (description string, rangeDescription string,
+                          scan func (ParserInput) (numberLiteral, bool),
+                          convert func (numberLiteral) (
+                            interface{}, error)) Parser {
+  return func (input ParserInput) ParserResult {
+    var literal, isLiteral = scan (input)
+    if !isLiteral {
+      return Failure (input, description)
+    }
+    var value, err = convert (literal)
+    if err != nil {
+      return ParserResult {
+        RemainingInput: input,
+        Error: newParseError (input, literal.width,
+                              []string { rangeDescription }) }
+    }
+    return ParserResult {
+      Result: value, RemainingInput: literal.remainingInput }
+  }
+}
+
+// scanSignedInteger scans an integer that may be negative.
+func scanSignedInteger (input ParserInput) (numberLiteral, bool) {
+  return scanInteger (input, "+-")
+}
+
+// scanUnsignedInteger scans an integer that can't be negative.
+func scanUnsignedInteger (input ParserInput) (numberLiteral, bool) {
+  return scanInteger (input, "+")
+}
+
+// ExpectInt64 parses an integer literal and the result will be an int64.
+// The literal may start with a sign and with one of the radix prefixes 0x,
+// 0o and 0b, and single underscores may separate its digits, like in Go:
+// -42, 0x_7f, 0b1010 or 1_000_000. Unlike in Go, a leading 0 doesn't mean
+// octal. If the literal doesn't fit into an int64 then the parse fails
+// without committing, so that you can fall back on ExpectBigInt.
+var ExpectInt64 Parser = expectNumberLiteral ("integer",
+  "integer in the range of int64", scanSignedInteger,
+  func (literal numberLiteral) (interface{}, error) {
+    var value, err = strconv.ParseInt (literal.sign () + literal.digits,
+                                       literal.base, 64)
+    return value, err
+  })
+
+// ExpectUint64 is like ExpectInt64 except that the result will be a uint64
+// and the literal may not start with a minus.
+var ExpectUint64 Parser = expectNumberLiteral ("unsigned integer",
+  "integer in the range of uint64", scanUnsignedInteger,
+  func (literal numberLiteral) (interface{}, error) {
+    var value, err = strconv.ParseUint (literal.digits, literal.base, 64)
+    return value, err
+  })
+
+// ExpectBigInt is like ExpectInt64 except that the result will be a *big.Int
+// which never overflows.
+var ExpectBigInt Parser = expectNumberLiteral ("integer", "integer",
+  scanSignedInteger,
+  func (literal numberLiteral) (interface{}, error) {
+    var value, _ = new (big.Int).SetString (literal.sign () + literal.digits,
+                                            literal.base)
+    return value, nil
+  })
+
+// ExpectFloat64 parses a decimal floating-point literal and the result will
+// be a float64. The literal may start with a sign, single underscores may
+// separate its digits and it may have a fraction and an exponent: 42, -.5,
+// 6.022_140e23 or 1E-9. A point must be followed by a digit, so 1. is just 1
+// followed by a point. If the literal is too large for a float64 then the
+// parse fails without committing.
+var ExpectFloat64 Parser = expectNumberLiteral ("floating-point number",
+  "floating-point number in the range of float64", scanFloat,
+  func (literal numberLiteral) (interface{}, error) {
+    var text = literal.sign () + "0" + literal.digits + "." +
+      literal.fraction + "0"
+    if literal.exponent != "" {
+      text += "e" + literal.exponent
+    }
+    var value, err = strconv.ParseFloat (text, 64)
+    return value, err
+  })
@@ -0,0 +1,145 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "math"
+  "math/big"
+  "strings"
+  "testing"
+)
+
+func TestExpectInt64 (t *testing.T) {
+  var tests = []struct {
+    input string
+    expected int64
+    rest string
+  } {
+    { "42", 42, "" },
+    { "-42+1", -42, "+1" },
+    { "+7", 7, "" },
+    { "007", 7, "" },
+    { "1_000_000", 1000000, "" },
+    { "1__0", 1, "__0" },
+    { "1_", 1, "_" },
+    { "0x_7f", 127, "" },
+    { "-0X7F", -127, "" },
+    { "0o17", 15, "" },
+    { "0b1010", 10, "" },
+    { "0b102", 2, "2" },
+    { "0xg", 0, "xg" },
+    { "9223372036854775807", math.MaxInt64, "" },
+    { "-9223372036854775808", math.MinInt64, "" },
+  }
+  for _, test := range tests {
+    var result = ExpectInt64 (StringToInput (test.input))
+    if result.Result != test.expected {
+      t.Errorf ("Expected %s to be %d, got %v and %v!",
+        test.input, test.expected, result.Result, result.Error)
+      continue
+    }
+    if rest := test.input[offsetOf (result.RemainingInput):];
+       rest != test.rest {
+      t.Errorf ("Expected %s to remain of %s, got %s!",
+        test.rest, test.input, rest)
+    }
+  }
+}
+
+func TestNumberErrors (t *testing.T) {
+  var tests = []struct {
+    parser Parser
+    input string
+    expected string
+  } {
+    { ExpectInt64, "x", "expected integer at 1:1, found 'x'" },
+    { ExpectInt64, "-", "expected integer at 1:1, found '-'" },
+    { ExpectInt64, "9223372036854775808", "expected integer in the range " +
+      "of int64 at 1:1, found '9223372036854775808'" },
+    { ExpectUint64, "-1", "expected unsigned integer at 1:1, found '-'" },
+    { ExpectUint64, "0x1_0000_0000_0000_0000 ", "expected integer in the " +
+      "range of uint64 at 1:1, found '0x1_0000_0000_0000_0000'" },
+    { ExpectFloat64, ".", "expected floating-point number at 1:1, " +
+      "found '.'" },
+    { ExpectFloat64, "1e400", "expected floating-point number in the " +
+      "range of float64 at 1:1, found '1e400'" },
+  }
+  for _, test := range tests {
+    var result = test.parser (StringToInput (test.input))
+    if result.Result != nil || result.Committed || result.Error == nil ||
+       result.Error.Error () != test.expected {
+      t.Errorf ("Expected the error %s for %s, got %v and %v!",
+        test.expected, test.input, result.Result, result.Error)
+    }
+  }
+}
+
+func TestExpectUint64 (t *testing.T) {
+  var result = ExpectUint64 (FileToInput (strings.NewReader (
+    "+18446744073709551615")))
+  if result.Result != uint64 (math.MaxUint64) {
+    t.Errorf ("Expected the largest uint64, got %v!", result.Result)
+  }
+}
+
+func TestExpectBigInt (t *testing.T) {
+  var parser = ExpectInt64.OrElse (ExpectBigInt)
+  var result = parser (StringToInput ("-0x1_0000_0000_0000_0000"))
+  var expected, _ = new (big.Int).SetString ("-18446744073709551616", 10)
+  var value, isBigInt = result.Result.(*big.Int)
+  if !isBigInt || value.Cmp (expected) != 0 {
+    t.Errorf ("Expected %v, got %v!", expected, result.Result)
+  }
+  if parser (StringToInput ("12")).Result != int64 (12) {
+    t.Errorf ("Expected small numbers to stay int64!")
+  }
+}
+
+func TestExpectFloat64 (t *testing.T) {
+  var tests = []struct {
+    input string
+    expected float64
+    rest string
+  } {
+    { "42", 42, "" },
+    { "-.5", -0.5, "" },
+    { "3.25x", 3.25, "x" },
+    { "6.022_140e23", 6.02214e23, "" },
+    { "1E-9", 1e-9, "" },
+    { "2e+3", 2000, "" },
+    { "1.", 1, "." },
+    { "1e", 1, "e" },
+    { "1e-", 1, "e-" },
+    { "0x10", 0, "x10" },
+  }
+  for _, test := range tests {
+    var result = ExpectFloat64 (StringToInput (test.input))
+    if result.Result != test.expected {
+      t.Errorf ("Expected %s to be %g, got %v and %v!",
+        test.input, test.expected, result.Result, result.Error)
+      continue
+    }
+    if rest := test.input[offsetOf (result.RemainingInput):];
+       rest != test.rest {
+      t.Errorf ("Expected %s to remain of %s, got %s!",
+        test.rest, test.input, rest)
+    }
+  }
+}
@@ -0,0 +1,226 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "math"
+)
+
+// Associativity tells how a chain of infix operators with the same binding
+// power is grouped.
+type Associativity int
+
+const (
+
+  // LeftAssociative groups "a - b - c" as "(a - b) - c".
+  LeftAssociative Associativity = iota
+
+  // RightAssociative groups "a ^ b ^ c" as "a ^ (b ^ c)".
+  RightAssociative
+
+  // NonAssociative doesn't allow chains like "a < b < c": the parser stops
+  // before the second operator.
+  NonAssociative
+)
+
+// unaryOperator is a prefix or postfix operator of an OperatorTable.
+type unaryOperator struct {
+  operator Parser
+  bindingPower int
+  build func (interface{}, interface{}) interface{}
+}
+
+// binaryOperator is an infix operator of an OperatorTable.
+type binaryOperator struct {
+  operator Parser
+  bindingPower int
+  associativity Associativity
+  build func (interface{}, interface{}, interface{}) interface{}
+}
+
+// OperatorTable declares the operators of an expression language, so that
+// you don't have to write one rule per level of precedence. Operators with
+// higher binding powers bind tighter. The callbacks build the results from
+// the results of the operator parsers and of the operands:
+//
+//   var Expression = NewOperatorTable ().
+//     Infix (expect ("+"), 1, LeftAssociative, add).
+//     Infix (expect ("-"), 1, LeftAssociative, subtract).
+//     Infix (expect ("*"), 2, LeftAssociative, multiply).
+//     Prefix (expect ("-"), 3, negate).
+//     Postfix (expect ("!"), 4, factorial).
+//     Parser (Number)
+//
+// Prefix operators are tried in the order of their declaration, and so are
+// the infix and postfix operators, so declare "<=" before "<" and a postfix
+// "++" before an infix "+".
+type OperatorTable struct {
+  prefix []unaryOperator
+  infix []binaryOperator
+  postfix []unaryOperator
+
+  // postfixOrder tells for every infix and postfix operator in the order of
+  // their declaration whether it's a postfix operator.
+  postfixOrder []bool
+}
+
+// NewOperatorTable creates a table without operators.
+func NewOperatorTable () *OperatorTable {
+  return &OperatorTable {}
+}
+
+// Prefix declares a prefix operator like the "-" in "-a". Its operand
+// contains all operators that bind at least as tightly as the prefix
+// operator. The build function receives the results of the operator and
+// of the operand.
+func (table *OperatorTable) Prefix (operator Parser, bindingPower int,
+               build func (operator interface{},
+                           operand interface{}) interface{}) *OperatorTable {
+  table.prefix = append (table.prefix,
+    unaryOperator { operator, bindingPower, build })
+  return table
+}
+
+// Infix declares an infix operator like the "+" in "a + b". The build
+// function receives the results of the operator and of both operands.
+func (table *OperatorTable) Infix (operator Parser, bindingPower int,
+               associativity Associativity,
+               build func (operator interface{}, left interface{},
+                           right interface{}) interface{}) *OperatorTable {
+  table.infix = append (table.infix,
+    binaryOperator { operator, bindingPower, associativity, build })
+  table.postfixOrder = append (table.postfixOrder, false)
+  return table
+}
+
+// Postfix declares a postfix operator like the "!" in "a!". The build
+// function receives the results of the operator and of the operand.
+func (table *OperatorTable) Postfix (operator Parser, bindingPower int,
+               build func (operator interface{},
+                           operand interface{}) interface{}) *OperatorTable {
+  table.postfix = append (table.postfix,
+    unaryOperator { operator, bindingPower, build })
+  table.postfixOrder = append (table.postfixOrder, true)
+  return table
+}
+
+// Parser creates the parser for expressions built from the atoms and the
+// operators of the table. Declaring more operators afterwards doesn't change
+// the parser.
+func (table *OperatorTable) Parser (atom Parser) Parser {
+  var operators = OperatorTable {
+    append ([]unaryOperator {}, table.prefix...),
+    append ([]binaryOperator {}, table.infix...),
+    append ([]unaryOperator {}, table.postfix...),
+    append ([]bool {}, table.postfixOrder...),
+  }
+  return operators.expression (atom, math.MinInt)
+}
+
+// expression parses an expression whose operators bind at least as tightly
+// as the minimumPower.
+func (table *OperatorTable) expression (atom Parser, minimumPower int) Parser {
+  return func (input ParserInput) ParserResult {
+    return table.operand (atom).Bind (func (left interface{}) Parser {
+      return table.operators (atom, left, minimumPower, math.MinInt)
+    }) (input)
+  }
+}
+
+// operand parses an atom or a prefix operator along with its operand.
+func (table *OperatorTable) operand (atom Parser) Parser {
+  var operand = atom
+  for i := len (table.prefix) - 1; i >= 0; i-- {
+    var prefix = table.prefix[i]
+    operand = prefix.operator.
+      AndThen (table.expression (atom, prefix.bindingPower)).
+      Convert (func (result interface{}) interface{} {
+        return prefix.build (GetFirst (result), GetSecond (result))
+      }).OrElse (operand)
+  }
+  return operand
+}
+
+// operators parses the postfix and infix operators after the left operand
+// as long as they bind at least as tightly as the minimumPower. After
+// a non-associative operator, operators of the same excludedPower end the
+// expression.
+func (table *OperatorTable) operators (atom Parser, left interface{},
+                                       minimumPower int,
+                                       excludedPower int) Parser {
+  var alternatives = succeed (left)
+  var i, j = len (table.infix), len (table.postfix)
+  for k := len (table.postfixOrder) - 1; k >= 0; k-- {
+    if table.postfixOrder[k] {
+      j--
+      alternatives = table.postfixOperator (atom, left, minimumPower,
+                                            table.postfix[j], alternatives)
+    } else {
+      i--
+      alternatives = table.infixOperator (atom, left, minimumPower,
+                                          excludedPower, table.infix[i],
+                                          alternatives)
+    }
+  }
+  return alternatives
+}
+
+// infixOperator parses the infix operator and the rest of the expression
+// after the left operand or else the alternatives.
+func (table *OperatorTable) infixOperator (atom Parser, left interface{},
+                                           minimumPower int,
+                                           excludedPower int,
+                                           infix binaryOperator,
+                                           alternatives Parser) Parser {
+  if infix.bindingPower < minimumPower ||
+     infix.associativity == NonAssociative &&
+       infix.bindingPower == excludedPower {
+    return alternatives
+  }
+  var rightPower = infix.bindingPower + 1
+  var nextExcludedPower = math.MinInt
+  if infix.associativity == RightAssociative {
+    rightPower = infix.bindingPower
+  } else if infix.associativity == NonAssociative {
+    nextExcludedPower = infix.bindingPower
+  }
+  return infix.operator.
+    AndThen (table.expression (atom, rightPower)).
+    Bind (func (result interface{}) Parser {
+      return table.operators (atom,
+        infix.build (GetFirst (result), left, GetSecond (result)),
+        minimumPower, nextExcludedPower)
+    }).OrElse (alternatives)
+}
+
+// postfixOperator parses the postfix operator and the rest of the
+// expression after the left operand or else the alternatives.
+func (table *OperatorTable) postfixOperator (atom Parser, left interface{},
+                                             minimumPower int,
+                                             postfix unaryOperator,
+                                             alternatives Parser) Parser {
+  if postfix.bindingPower < minimumPower {
+    return alternatives
+  }
+  return postfix.operator.Bind (func (operator interface{}) Parser {
+    return table.operators (atom, postfix.build (operator, left),
+                            minimumPower, math.MinInt)
+  }).OrElse (alternatives)
+}
@@ -0,0 +1,144 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "fmt"
+  "strings"
+  "testing"
+)
+
+// operator parses an operator and returns its text.
+func operator (text string) Parser {
+  return MaybeSpacesBefore (ExpectString (text))
+}
+
+// parenthesizePrefix shows where a prefix operator was applied.
+func parenthesizePrefix (operator interface{},
+                         operand interface{}) interface{} {
+  return fmt.Sprintf ("(%s%s)", operator, operand)
+}
+
+// parenthesizePostfix shows where a postfix operator was applied.
+func parenthesizePostfix (operator interface{},
+                          operand interface{}) interface{} {
+  return fmt.Sprintf ("(%s%s)", operand, operator)
+}
+
+// parenthesizeInfix shows where an infix operator was applied.
+func parenthesizeInfix (operator interface{}, left interface{},
+                        right interface{}) interface{} {
+  return fmt.Sprintf ("(%s%s%s)", left, operator, right)
+}
+
+// operatorGrammar parses expressions with all kinds of operators and shows
+// their grouping with parentheses.
+func operatorGrammar () Parser {
+  var expression Parser
+  var atom = MaybeSpacesBefore (ExpectIdentifier).OrElse (
+    operator ("(").AndThen (func (input ParserInput) ParserResult {
+      return expression (input)
+    }).AndThen (operator (")")).First ().Second ())
+  expression = NewOperatorTable ().
+    Infix (operator ("=="), 1, NonAssociative, parenthesizeInfix).
+    Infix (operator ("<"), 1, NonAssociative, parenthesizeInfix).
+    Infix (operator ("+"), 2, LeftAssociative, parenthesizeInfix).
+    Infix (operator ("-"), 2, LeftAssociative, parenthesizeInfix).
+    Infix (operator ("*"), 3, LeftAssociative, parenthesizeInfix).
+    Prefix (operator ("-"), 4, parenthesizePrefix).
+    Infix (operator ("^"), 5, RightAssociative, parenthesizeInfix).
+    Postfix (operator ("!"), 6, parenthesizePostfix).
+    Parser (atom)
+  return expression
+}
+
+func TestOperatorTable (t *testing.T) {
+  var expression = operatorGrammar ()
+  var tests = []struct { input, expected string } {
+    { "a", "a" },
+    { "a + b * c", "(a+(b*c))" },
+    { "a * b + c", "((a*b)+c)" },
+    { "a - b - c", "((a-b)-c)" },
+    { "a ^ b ^ c", "(a^(b^c))" },
+    { "-a * b", "((-a)*b)" },
+    { "-a ^ b", "(-(a^b))" },
+    { "- -a!", "(-(-(a!)))" },
+    { "a! ^ b!", "((a!)^(b!))" },
+    { "a - -b", "(a-(-b))" },
+    { "(a + b) * c", "((a+b)*c)" },
+    { "a + b < c * d", "((a+b)<(c*d))" },
+    { "a < b == c", "(a<b)" },
+    { "a == b == c", "(a==b)" },
+  }
+  for _, test := range tests {
+    var result = expression (StringToInput (test.input))
+    if result.Result != test.expected {
+      t.Errorf ("Expected %s to be parsed as %s, got %v!",
+        test.input, test.expected, result.Result)
+    }
+  }
+}
+
+func TestOperatorTableErrors (t *testing.T) {
+  var expression = operatorGrammar ()
+  var result = expression.ParseAll (StringToInput ("a + * b"))
+  if result.Result != nil || !result.Committed {
+    t.Errorf ("Expected a committed failure after +, got %v!", result.Result)
+  }
+  if result.Error == nil || result.Error.Position.Offset != 4 {
+    t.Errorf ("Expected the error in front of *, got %v!", result.Error)
+  }
+  result = expression.ParseAll (StringToInput ("a < b < c"))
+  if result.Result != nil || result.Error == nil ||
+     result.Error.Position.Offset != 6 {
+    t.Errorf ("Expected the non-associative < to stop before the second " +
+      "<, got %v and %v!", result.Result, result.Error)
+  }
+}
+
+func TestOperatorTableOnFileInput (t *testing.T) {
+  var result = operatorGrammar ().ParseAll (
+    FileToInput (strings.NewReader ("a * (b + c)!")))
+  if result.Result != "(a*((b+c)!))" {
+    t.Errorf ("Expected (a*((b+c)!)), got %v!", result.Result)
+  }
+}
+
+func TestOperatorTableDeclarationOrder (t *testing.T) {
+  var atom = MaybeSpacesBefore (ExpectIdentifier)
+  var postfixFirst = NewOperatorTable ().
+    Postfix (operator ("++"), 2, parenthesizePostfix).
+    Infix (operator ("+"), 1, LeftAssociative, parenthesizeInfix).
+    Parser (atom)
+  if result := postfixFirst.ParseAll (StringToInput ("a++ + b"));
+     result.Result != "((a++)+b)" {
+    t.Errorf ("Expected ((a++)+b), got %v and %v!",
+      result.Result, result.Error)
+  }
+  var infixFirst = NewOperatorTable ().
+    Infix (operator ("+"), 1, LeftAssociative, parenthesizeInfix).
+    Postfix (operator ("++"), 2, parenthesizePostfix).
+    Parser (atom)
+  if result := infixFirst.ParseAll (StringToInput ("a++ + b"));
+     result.Result != nil {
+    t.Errorf ("Expected the infix + to be tried before the postfix ++, " +
+      "got %v!", result.Result)
+  }
+}
@@ -32,16 +32,31 @@ type Parser func (ParserInput) ParserResult
 
 // ParserInput is anything that can produce a sequence of code points.
 // RuneArrayInput is one implementation that you can use. See StringToInput
-// if you want to create ParserInput directly from a string.
+// if you want to create ParserInput directly from a string. Every input ends
+// with an explicit end of input where AtEnd is true, even an empty one.
 type ParserInput interface {
 
-  // CurrentCodePoint returns the rune at the beginning of this input
+  // CurrentCodePoint returns the rune at the beginning of this input, or
+  // EndOfInput at the end of the input.
   CurrentCodePoint () rune
 
   // RemainingInput returns everything that comes after the current code point.
+  // At the end of the input it returns the end of the input again.
   RemainingInput () ParserInput
+
+  // Position tells where the current code point is located in the input.
+  // At the end of the input it's the position after the last code point.
+  Position () Position
+
+  // AtEnd is true iff there are no code points left in the input.
+  AtEnd () bool
 }
 
+// EndOfInput is the CurrentCodePoint at the end of the input. It's not
+// a valid code point, so it can't be confused with anything in the input,
+// not even with '\x00'.
+const EndOfInput rune = -1
+
 // ParserResult is the result of a parse along with the input that remains to
 // be parsed.
 type ParserResult struct {
@@ -54,58 +69,160 @@ type ParserResult struct {
   // Result. If the parse failed then it's just the input from before the
   // parsing attempt.
   RemainingInput ParserInput
+
+  // Error is the furthest failure that happened during the parse. The parsers
+  // of this package always set it when parsing fails. A successful parse
+  // may keep the error of an attempt that failed at or after its end so that
+  // a subsequent failure can tell what else would have been accepted.
+  Error *ParseError
+
+  // Committed is true if a failed parse consumed input or passed a Cut
+  // before it failed. OrElse doesn't try alternatives after committed
+  // failures unless you wrap the parser in Try. If a successful parse passed
+  // a Cut then Committed is true as well, so that a failure of the parsers
+  // after it in the same sequence is committed, too.
+  Committed bool
+
+  // Recovered lists the errors that Recover recovered from during a
+  // successful parse, in the order in which they occur in the input.
+  Recovered []*ParseError
 }
 
 // ExpectCodePoint expects exactly one rune in the input. If the input
 // starts with this rune it will become the result.
 func ExpectCodePoint (expectedCodePoint rune) Parser {
   return func (input ParserInput) ParserResult {
-    if expectedCodePoint == input.CurrentCodePoint () {
-      return ParserResult { expectedCodePoint, input.RemainingInput () }
+    if !input.AtEnd () && expectedCodePoint == input.CurrentCodePoint () {
+      return ParserResult {
+        Result: expectedCodePoint, RemainingInput: input.RemainingInput () }
     }
-    return ParserResult { nil, input }
+    return Failure (input, quote (string (expectedCodePoint)))
   }
 }
 
 // Fail just is a failing parser. No tricks.
 var Fail Parser = func (input ParserInput) ParserResult {
-  return ParserResult { nil, input }
+  return Failure (input)
+}
+
+// succeed doesn't consume any input and always produces the result.
+func succeed (result interface{}) Parser {
+  return func (input ParserInput) ParserResult {
+    return ParserResult { Result: result, RemainingInput: input }
+  }
+}
+
+// ExpectEnd succeeds with the result Nothing{} at the end of the input and
+// fails everywhere else.
+var ExpectEnd Parser = func (input ParserInput) ParserResult {
+  if input.AtEnd () {
+    return ParserResult { Result: Nothing {}, RemainingInput: input }
+  }
+  return Failure (input, "end of input")
+}
+
+// ParseAll applies the parser to the input and fails unless the parser
+// consumes all of it. Use it to parse whole texts, so that you don't have to
+// check the RemainingInput yourself.
+func (parser Parser) ParseAll (input ParserInput) ParserResult {
+  return parser.AndThen (ExpectEnd).First () (input)
 }
 
 // ExpectNotCodePoint expects exactly one rune in the input that does not
 // appear in the forbiddenCodePoints.
 func ExpectNotCodePoint (forbiddenCodePoints []rune) Parser {
   return func (input ParserInput) ParserResult {
+    if input.AtEnd () {
+      return Failure (input)
+    }
     for _, forbiddenCodePoint := range forbiddenCodePoints {
       if input.CurrentCodePoint () == forbiddenCodePoint {
-        return ParserResult { nil, input }
+        return Failure (input)
+      }
+    }
+    return ParserResult {
+      Result: input.CurrentCodePoint (),
+      RemainingInput: input.RemainingInput () }
+  }
+}
+
+// CodePointRange contains the code points from First up to Last.
+type CodePointRange struct {
+
+  // First is the smallest code point of the range.
+  First rune
+
+  // Last is the largest code point of the range.
+  Last rune
+}
+
+// ExpectCodePointIn expects exactly one rune in the input that lies in one of
+// the ranges. The description names the ranges in error messages, e.g. "[a-z]".
+func ExpectCodePointIn (description string, ranges ...CodePointRange) Parser {
+  return expectCodePointInRanges (description, ranges, true)
+}
+
+// ExpectCodePointNotIn expects exactly one rune in the input that lies in none
+// of the ranges.
+func ExpectCodePointNotIn (description string,
+                           ranges ...CodePointRange) Parser {
+  return expectCodePointInRanges (description, ranges, false)
+}
+
+// expectCodePointInRanges implements ExpectCodePointIn and
+// ExpectCodePointNotIn.
+func expectCodePointInRanges (description string, ranges []CodePointRange,
+                              inside bool) Parser {
+  return func (input ParserInput) ParserResult {
+    if input.AtEnd () {
+      return Failure (input, description)
+    }
+    var codePoint = input.CurrentCodePoint ()
+    var isInRange = false
+    for _, codePointRange := range ranges {
+      if codePointRange.First <= codePoint && codePoint <= codePointRange.Last {
+        isInRange = true
+        break
       }
     }
-    return ParserResult { input.CurrentCodePoint (), input.RemainingInput () }
+    if isInRange != inside {
+      return Failure (input, description)
+    }
+    return ParserResult {
+      Result: codePoint, RemainingInput: input.RemainingInput () }
   }
 }
 
 // ExpectCodePoints expects exactly the code points from the slice
 // expectedCodePoints at the beginning of the input in the given order.
 // If the input begins with these code points then expectedCodePoints will
-// be the result of the parse.
+// be the result of the parse. If the input only begins with some of the
+// code points then the failure isn't committed.
 func ExpectCodePoints (expectedCodePoints []rune) Parser {
   return func (input ParserInput) ParserResult {
     var RemainingInput = input
     for _, expectedCodePoint := range expectedCodePoints {
-      if nil == RemainingInput {
-        return ParserResult { nil, RemainingInput }
-      }
-      var result = ExpectCodePoint (expectedCodePoint) (RemainingInput)
-      if result.Result == nil {
-        return ParserResult { nil, RemainingInput }
+      if RemainingInput.AtEnd () ||
+         RemainingInput.CurrentCodePoint () != expectedCodePoint {
+        return ParserResult {
+          RemainingInput: input,
+          Error: expectedCodePointsError (input, expectedCodePoints) }
       }
-      RemainingInput = result.RemainingInput
+      RemainingInput = RemainingInput.RemainingInput ()
     }
-    return ParserResult { expectedCodePoints, RemainingInput }
+    return ParserResult {
+      Result: expectedCodePoints, RemainingInput: RemainingInput }
   }
 }
 
+// expectedCodePointsError reports that the input doesn't begin with the
+// expectedCodePoints.
+func expectedCodePointsError (input ParserInput,
+                              expectedCodePoints []rune) *ParseError {
+  return newParseError (input, len (expectedCodePoints),
+                        []string { quote (string (expectedCodePoints)) })
+}
+
 // ExpectString expects the input to begin with the code points from the
 // expectedString in the given order. If the input starts with these code
 // points then expectedString will be the result of the parse.
@@ -121,30 +238,29 @@ func ExpectString(expectedString string) Parser {
 }
 
 // Repeated applies a parser zero or more times and accumulates the results
-// of the parses in a list. This parse always produces a non-nil result.
+// of the parses in a list. This parse produces a non-nil result unless the
+// parser fails after committing. It stops as soon as the parser succeeds
+// without consuming any input.
 func (parser Parser) Repeated () Parser {
   return func (input ParserInput) ParserResult {
-    var result = ParserResult { list.New (), input }
-    for result.RemainingInput != nil {
-      var oneMoreResult = parser (result.RemainingInput)
-      if oneMoreResult.Result == nil {
-        return result
-      }
-      result.Result.(*list.List).PushBack (oneMoreResult.Result)
-      result.RemainingInput = oneMoreResult.RemainingInput
-    }
-    return result
+    return parser.repeat (input, list.New (), pushBack)
   }
 }
 
+// pushBack adds the result to the end of the list of results.
+func pushBack (results interface{}, result interface{}) interface{} {
+  results.(*list.List).PushBack (result)
+  return results
+}
+
 // OnceOrMore is like Repeated except that it doesn't allow parsing zero times.
 func (parser Parser) OnceOrMore () Parser {
   return func (input ParserInput) ParserResult {
     var result = parser.Repeated () (input)
-    if result.Result.(*list.List).Len () > 0 {
+    if result.Result == nil || result.Result.(*list.List).Len () > 0 {
       return result
     }
-    return ParserResult { nil, input }
+    return ParserResult { RemainingInput: input, Error: result.Error }
   }
 }
 
@@ -158,27 +274,71 @@ func (parser Parser) RepeatAndFoldLeft (accumulator interface{},
                                 combine func (interface{},
                                               interface{}) interface{}) Parser {
   return func (input ParserInput) ParserResult {
-    var result = ParserResult { accumulator, input }
-    for result.RemainingInput != nil {
-      var oneMoreResult = parser (result.RemainingInput)
-      if oneMoreResult.Result == nil {
-        return result
-      }
-      result.Result = combine (result.Result, oneMoreResult.Result)
-      result.RemainingInput = oneMoreResult.RemainingInput
+    return parser.repeat (input, accumulator, combine)
+  }
+}
+
+// repeat implements Repeated and RepeatAndFoldLeft.
+func (parser Parser) repeat (input ParserInput, accumulator interface{},
+                             combine func (interface{},
+                                           interface{}) interface{}) ParserResult {
+  var result = ParserResult { Result: accumulator, RemainingInput: input }
+  for {
+    var oneMoreResult = parser (result.RemainingInput)
+    if oneMoreResult.Result == nil && oneMoreResult.Committed {
+      return failedSequence (input, result, oneMoreResult)
     }
-    return result
+    result.Error = mergeErrors (result.Error, oneMoreResult.Error)
+    if oneMoreResult.Result == nil ||
+       !consumed (result.RemainingInput, oneMoreResult.RemainingInput) {
+      return result
+    }
+    result.Result = combine (result.Result, oneMoreResult.Result)
+    result.RemainingInput = oneMoreResult.RemainingInput
+    result.Committed = result.Committed || oneMoreResult.Committed
+    result.Recovered = concatErrors (result.Recovered, oneMoreResult.Recovered)
   }
 }
 
+// consumed is true iff a parse that started at the input and stopped at the
+// remainingInput consumed anything.
+func consumed (input ParserInput, remainingInput ParserInput) bool {
+  return offsetOf (remainingInput) > offsetOf (input)
+}
+
+// failedSequence turns the failure of a parser that was applied after the
+// successful firstResult into the failure of the whole sequence, which
+// started at the input. The failure is committed if the first parser
+// consumed input or passed a Cut.
+func failedSequence (input ParserInput, firstResult ParserResult,
+                     secondResult ParserResult) ParserResult {
+  return ParserResult {
+    RemainingInput: input,
+    Error: mergeErrors (firstResult.Error, secondResult.Error),
+    Committed: secondResult.Committed || firstResult.Committed ||
+      consumed (input, firstResult.RemainingInput) }
+}
+
 // Bind uses the result of a first parser to construct a second parser that
 // will parse the left-over input from the first parser. You can use this
-// to implement syntax annotations.
+// to implement syntax annotations. If the first parser fails then Bind fails
+// without calling the constructor.
 func (parser Parser) Bind (constructor func (interface{}) Parser) Parser {
   return func (input ParserInput) ParserResult {
     var firstResult = parser (input)
+    if firstResult.Result == nil {
+      return firstResult
+    }
     var secondParser = constructor (firstResult.Result)
-    return secondParser (firstResult.RemainingInput)
+    var secondResult = secondParser (firstResult.RemainingInput)
+    if secondResult.Result == nil {
+      return failedSequence (input, firstResult, secondResult)
+    }
+    secondResult.Error = mergeErrors (firstResult.Error, secondResult.Error)
+    secondResult.Committed = firstResult.Committed || secondResult.Committed
+    secondResult.Recovered =
+      concatErrors (firstResult.Recovered, secondResult.Recovered)
+    return secondResult
   }
 }
 
@@ -189,13 +349,44 @@ func (parser Parser) Bind (constructor func (interface{}) Parser) Parser {
 // then it will not attempt to use the second parser and there's no
 // back-tracking. This is in contrast to most regex-libs where the longest
 // match wins. The first match wins here, please keep this in mind.
+// If the first parser fails after consuming some input then the failure is
+// committed and OrElse won't try the second parser either. Use Try if you
+// need the second parser in this case.
 func (parser Parser) OrElse (alternativeParser Parser) Parser {
   return func (input ParserInput) ParserResult {
     var FirstResult = parser (input)
-    if FirstResult.Result != nil {
+    if FirstResult.Result != nil || FirstResult.Committed {
       return FirstResult
     }
-    return alternativeParser (input)
+    var secondResult = alternativeParser (input)
+    secondResult.Error = mergeErrors (FirstResult.Error, secondResult.Error)
+    return secondResult
+  }
+}
+
+// Try makes the failures of the parser uncommitted, so that OrElse tries
+// the alternatives even if the parser consumed some input before it failed.
+func Try (parser Parser) Parser {
+  return func (input ParserInput) ParserResult {
+    var result = parser (input)
+    if result.Result == nil {
+      result.Committed = false
+    }
+    return result
+  }
+}
+
+// Cut applies the parser and commits to its success: if a parser after it
+// in the same sequence fails then the failure is committed, even if no input
+// was consumed. A Try around the sequence still makes the failure
+// uncommitted.
+func (parser Parser) Cut () Parser {
+  return func (input ParserInput) ParserResult {
+    var result = parser (input)
+    if result.Result != nil {
+      result.Committed = true
+    }
+    return result
   }
 }
 
@@ -237,27 +428,53 @@ func GetFirst (argument interface {}) interface {} {
 func (firstParser Parser) AndThen (secondParser Parser) Parser {
   return func (input ParserInput) ParserResult {
     var firstResult = firstParser (input)
-    if firstResult.Result != nil {
-      var secondResult = secondParser (firstResult.RemainingInput)
-      if secondResult.Result != nil {
-        return ParserResult {
-          Pair { firstResult.Result, secondResult.Result },
-          secondResult.RemainingInput }
-      }
-      return secondResult
+    if firstResult.Result == nil {
+      return firstResult
     }
-    return firstResult
+    var secondResult = secondParser (firstResult.RemainingInput)
+    if secondResult.Result == nil {
+      return failedSequence (input, firstResult, secondResult)
+    }
+    return ParserResult {
+      Result: Pair { firstResult.Result, secondResult.Result },
+      RemainingInput: secondResult.RemainingInput,
+      Error: mergeErrors (firstResult.Error, secondResult.Error),
+      Committed: firstResult.Committed || secondResult.Committed,
+      Recovered: concatErrors (firstResult.Recovered, secondResult.Recovered) }
   }
 }
 
+// Sequence applies the parsers one after the other and collects their
+// results in a []interface{}. It's like a chain of AndThen without the nested
+// pairs.
+func Sequence (parsers ...Parser) Parser {
+  var sequence = succeed ([]interface{} {})
+  for _, parser := range parsers {
+    sequence = sequence.AndThen (parser).
+      Convert (func (results interface{}) interface{} {
+        var previous = GetFirst (results).([]interface{})
+        return append (previous[:len (previous):len (previous)],
+                       GetSecond (results))
+      })
+  }
+  return sequence
+}
+
 // Convert applies the converter to the result of a successful parse.
-// If the parser fails then Convert won't do anything.
+// If the parser fails then Convert won't do anything. The converter may
+// reject the result by returning nil, which makes the parse fail without
+// committing, as if the parser itself had failed right at the beginning.
 func (parser Parser) Convert (
                         converter func (interface {}) interface {}) Parser {
   return func (input ParserInput) ParserResult {
     var result = parser (input)
     if result.Result != nil {
       result.Result = converter (result.Result)
+      if result.Result == nil {
+        return ParserResult {
+          RemainingInput: input,
+          Error: mergeErrors (result.Error, Failure (input).Error) }
+      }
     }
     return result
   }
@@ -283,11 +500,12 @@ type Nothing struct {}
 
 // Optional applies the parser zero or one times to the input.
 // If the parser itself would fail then the Optional parser can still
-// produce a successful parse with the result Nothing{}.
+// produce a successful parse with the result Nothing{}, unless the failure
+// is committed.
 func (parser Parser) Optional () Parser {
   return func (input ParserInput) ParserResult {
     var result = parser (input)
-    if result.Result == nil {
+    if result.Result == nil && !result.Committed {
       result.Result = Nothing {}
       result.RemainingInput = input
     }
@@ -303,20 +521,26 @@ type FileInput struct {
   // File is the underlying file of this parser input
   File        io.RuneReader
 
-  // CurrentRune is the current character
+  // CurrentRune is the current character or EndOfInput
   CurrentRune rune
 
   // RestOfInput is what remains after the CurrentRune
   RestOfInput *FileInput
+
+  // position is where the CurrentRune is located in the file
+  position Position
+
+  // width is the number of bytes that the CurrentRune took up in the file
+  width int
 }
 
 // FileToInput converts a RuneReader into a ParserInput.
 func FileToInput (file io.RuneReader) *FileInput {
-  var r, _, err = file.ReadRune ()
+  var r, width, err = file.ReadRune ()
   if err != nil {
-    return &FileInput { file, '\x00', nil }
+    return &FileInput { file, EndOfInput, nil, startPosition, 0 }
   }
-  return &FileInput { file, r, nil }
+  return &FileInput { file, r, nil, startPosition, width }
 }
 
 // FilenameToInput opens a file and converts it into ParserInput.
@@ -330,18 +554,17 @@ func FilenameToInput (filename string) *FileInput {
 
 // RemainingInput is necessary for FileInput to implement ParserInput
 func (input *FileInput) RemainingInput () ParserInput {
-  if input.RestOfInput != nil {
-    return input.RestOfInput
-  }
-  if input.File == nil {
-    return nil
+  if input.AtEnd () {
+    return input
   }
-  var r, _, err = input.File.ReadRune ()
-  if err != nil {
-    input.File = nil
-    return nil
+  if input.RestOfInput == nil {
+    var r, width, err = input.File.ReadRune ()
+    if err != nil {
+      r, width = EndOfInput, 0
+    }
+    input.RestOfInput = &FileInput { input.File, r, nil,
+      input.position.advance (input.CurrentRune, input.width), width }
   }
-  input.RestOfInput = &FileInput { input.File, r, nil }
   return input.RestOfInput
 }
 
@@ -350,6 +573,16 @@ func (input *FileInput) CurrentCodePoint () rune {
   return input.CurrentRune
 }
 
+// Position is necessary for FileInput to implement ParserInput
+func (input *FileInput) Position () Position {
+  return input.position
+}
+
+// AtEnd is necessary for FileInput to implement ParserInput
+func (input *FileInput) AtEnd () bool {
+  return input.CurrentRune == EndOfInput
+}
+
 // RuneArrayInput is an implementation of ParserInput.
 // You can use StringToInput to create instances of this type directly
 // from strings.
@@ -363,26 +596,62 @@ type RuneArrayInput struct {
   CurrentPosition int
 }
 
-// RemainingInput is necessary for RuneArrayInput to implement ParserInput
+// RemainingInput is necessary for RuneArrayInput to implement ParserInput.
+// The remaining inputs share an index of the lines of the Text, so that they
+// compute their positions quickly.
 func (input RuneArrayInput) RemainingInput () ParserInput {
-  if input.CurrentPosition + 1 >= len (input.Text) {
-    return nil
-  }
-  return RuneArrayInput { input.Text, input.CurrentPosition + 1 }
+  return indexedRuneArrayInput { input, &lineIndex {} }.RemainingInput ()
 }
 
 // CurrentCodePoint is necessary for RuneArrayInput to implement ParserInput
 func (input RuneArrayInput) CurrentCodePoint () rune {
-  if input.CurrentPosition >= len (input.Text) {
-    return '\x00' // only happens with empty input now?!
+  if input.AtEnd () {
+    return EndOfInput
   }
   return input.Text[input.CurrentPosition]
 }
 
+// AtEnd is necessary for RuneArrayInput to implement ParserInput
+func (input RuneArrayInput) AtEnd () bool {
+  return input.CurrentPosition >= len (input.Text)
+}
+
+// Position is necessary for RuneArrayInput to implement ParserInput. It
+// scans the Text up to the CurrentPosition.
+func (input RuneArrayInput) Position () Position {
+  var position = startPosition
+  for _, codePoint := range input.Text[:input.CurrentPosition] {
+    position = position.advance (codePoint, runeWidth (codePoint))
+  }
+  return position
+}
+
+// indexedRuneArrayInput is a RuneArrayInput along with the index of the
+// lines of its Text, which all inputs after it share.
+type indexedRuneArrayInput struct {
+  RuneArrayInput
+  lines *lineIndex
+}
+
+// RemainingInput is the input after the current code point.
+func (input indexedRuneArrayInput) RemainingInput () ParserInput {
+  if input.AtEnd () {
+    return input
+  }
+  return indexedRuneArrayInput {
+    RuneArrayInput { input.Text, input.CurrentPosition + 1 }, input.lines }
+}
+
+// Position computes the position with the index of the lines.
+func (input indexedRuneArrayInput) Position () Position {
+  return input.lines.position (input.Text, input.CurrentPosition)
+}
+
 // StringToInput converts a string to a RuneArrayInput so you can use parsers
 // on it.
 func StringToInput (Text string) ParserInput {
-  return RuneArrayInput { []rune(Text), 0 }
+  return indexedRuneArrayInput {
+    RuneArrayInput { []rune(Text), 0 }, &lineIndex {} }
 }
 
 func isIdentifierStartChar (FirstCodePoint rune) bool {
@@ -412,32 +681,26 @@ func isSpaceChar (codePoint rune) bool {
 func ExpectSeveral (isFirstChar func (rune) bool,
                     isLaterChar func (rune) bool) Parser {
   return func (input ParserInput) ParserResult {
-    if nil == input {
-      return ParserResult { nil, input }
-    }
-    var FirstCodePoint = input.CurrentCodePoint ()
-    if !isFirstChar (FirstCodePoint) {
-      return ParserResult { nil, input }
+    if input.AtEnd () || !isFirstChar (input.CurrentCodePoint ()) {
+      return Failure (input)
     }
     var builder strings.Builder
-    var codePoint = FirstCodePoint
-    var RemainingInput = input
-    for isLaterChar (codePoint) {
-      builder.WriteRune (codePoint)
+    builder.WriteRune (input.CurrentCodePoint ())
+    var RemainingInput = input.RemainingInput ()
+    for !RemainingInput.AtEnd () &&
+        isLaterChar (RemainingInput.CurrentCodePoint ()) {
+      builder.WriteRune (RemainingInput.CurrentCodePoint ())
       RemainingInput = RemainingInput.RemainingInput ()
-      if RemainingInput == nil {
-        break
-      } else {
-        codePoint = RemainingInput.CurrentCodePoint ()
-      }
     }
-    return ParserResult { builder.String (), RemainingInput }
+    return ParserResult {
+      Result: builder.String (), RemainingInput: RemainingInput }
   }
 }
 
 // ExpectIdentifier parses a [a-zA-Z_][a-zA-Z0-9_]* from the input.
 var ExpectIdentifier Parser =
-  ExpectSeveral (isIdentifierStartChar, isIdentifierChar)
+  ExpectSeveral (isIdentifierStartChar, isIdentifierChar).
+    Expecting ("identifier")
 
 // ExpectSpaces parses a [ \t\n\r]* from the input.
 var ExpectSpaces Parser =
@@ -446,10 +709,19 @@ var ExpectSpaces Parser =
 // ExpectNumber parses a [0-9]+ from the input and the result will be a string.
 // You need to convert it into your favorite number type by yourself.
 var ExpectNumber Parser =
-  ExpectSeveral (isDigit, isDigit)
+  ExpectSeveral (isDigit, isDigit).Expecting ("number")
 
 // MaybeSpacesBefore allows and ignores space characters before applying the
-// parser from the argument.
+// parser from the argument. The spaces don't count as consumed input: if the
+// parser fails without consuming anything then the failure isn't committed.
 func MaybeSpacesBefore (parser Parser) Parser {
-  return Parser (ExpectSpaces).AndThen (parser).Second ()
+  return func (input ParserInput) ParserResult {
+    var spaces = ExpectSpaces (input)
+    var result = parser (spaces.RemainingInput)
+    result.Error = mergeErrors (spaces.Error, result.Error)
+    if result.Result == nil {
+      result.RemainingInput = input
+    }
+    return result
+  }
 }
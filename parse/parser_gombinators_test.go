@@ -22,12 +22,14 @@ package parse
 import (
   "testing"
   "container/list"
+  "reflect"
+  "strings"
 )
 
 func testExpectedCharacterInInput (t *testing.T,
                                    codePoint rune, input ParserInput) {
-  if input == nil {
-    t.Errorf ("Expected %c but the input is nil!\n", codePoint)
+  if input.AtEnd () {
+    t.Errorf ("Expected %c but the input is at its end!\n", codePoint)
   } else if codePoint != input.CurrentCodePoint () {
     t.Errorf ("Expected %c, got %c!\n", codePoint, input.CurrentCodePoint ())
   }
@@ -43,9 +45,10 @@ func TestRuneArrayInput (t *testing.T) {
   testExpectedCharacterInInput (t, '猫', input)
   testExpectedCharacterInInput (t, '熊', input2)
   input = input.RemainingInput ()
-  if input != nil {
+  if !input.AtEnd () || input.CurrentCodePoint () != EndOfInput ||
+     !input.RemainingInput ().AtEnd () {
     t.Errorf (
-      "Expected the remaining input to be nil after the end of the input!")
+      "Expected the remaining input to stay at the end of the input!")
   }
 }
 
@@ -83,7 +86,7 @@ func TestRepeated (t *testing.T) {
   input = StringToInput ("")
   result = parser (input)
   if result.Result.(*list.List).Len () != 0 ||
-     result.RemainingInput.CurrentCodePoint () != rune ('\x00') {
+     result.RemainingInput.CurrentCodePoint () != EndOfInput {
     t.Errorf ("Expected the parser to successfully parse nothing!")
   }
 }
@@ -121,7 +124,7 @@ func TestOrElse (t *testing.T) {
   result = parser (input)
   if result.Result != "B" ||
     result.RemainingInput.CurrentCodePoint () != 'C' {
-    t.Errorf ("Expected the parser to parse the First code point! %s", result)
+    t.Errorf ("Expected the parser to parse the First code point! %v", result)
   }
 
 }
@@ -137,7 +140,7 @@ func TestAndThen (t *testing.T) {
   result = parser (input)
   if result.Result.(Pair).First != "A" ||
     result.Result.(Pair).Second != "B" ||
-    result.RemainingInput != nil {
+    !result.RemainingInput.AtEnd () {
     t.Errorf ("Expected the parser to parse (A, B)!")
   }
   input = StringToInput ("ABC")
@@ -223,7 +226,7 @@ func TestBind (t *testing.T) {
   var input = StringToInput ("ning ning")
   var result = parser (input)
   if result.Result != "ning" ||
-    result.RemainingInput != nil {
+    !result.RemainingInput.AtEnd () {
     t.Errorf ("Expected the parser to eat up the whole input!")
   }
 }
@@ -237,3 +240,82 @@ func TestFileInput (t *testing.T) {
     t.Errorf ("Expected the parser to read until the C.")
   }
 }
+
+func TestEndOfInput (t *testing.T) {
+  for _, input := range []ParserInput {
+      StringToInput (""), FileToInput (strings.NewReader ("")),
+      UTF8StringToInput (""), BytesToInput (nil), TokensToInput (nil),
+      NewStream (strings.NewReader ("")).Input (),
+      Memoized (StringToInput ("")) } {
+    if !input.AtEnd () || input.CurrentCodePoint () != EndOfInput ||
+       !input.RemainingInput ().AtEnd () {
+      t.Errorf ("Expected %T to be at the end of the input!", input)
+    }
+  }
+  var input = FileToInput (strings.NewReader ("\x00"))
+  if input.AtEnd () || ExpectCodePoint ('\x00') (input).Result == nil {
+    t.Errorf ("Expected NUL to be a code point like any other!")
+  }
+  var end = input.RemainingInput ()
+  if !end.AtEnd () || end.Position ().Offset != 1 {
+    t.Errorf ("Expected the end of the input after the NUL, got %v!",
+      end.Position ())
+  }
+}
+
+func TestExpectEnd (t *testing.T) {
+  var parser = ExpectString ("a").Repeated ()
+  var result = parser.ParseAll (StringToInput ("aa"))
+  if result.Result.(*list.List).Len () != 2 ||
+     !result.RemainingInput.AtEnd () {
+    t.Errorf ("Expected the parser to parse all of the input!")
+  }
+  result = parser.ParseAll (StringToInput ("aab"))
+  if result.Result != nil {
+    t.Errorf ("Expected the parser to fail before the end of the input!")
+  }
+  testErrorMessage (t, result, "expected 'a' or end of input at 1:3, found 'b'")
+  result = ExpectEnd (StringToInput ("a").RemainingInput ())
+  if result.Result == nil {
+    t.Errorf ("Expected ExpectEnd to succeed at the end of the input!")
+  }
+}
+
+func TestSequence (t *testing.T) {
+  var parser = Sequence (ExpectString ("a"), ExpectNumber, ExpectString ("b"))
+  var result = parser (StringToInput ("a42b"))
+  var expected = []interface{} { "a", "42", "b" }
+  if !reflect.DeepEqual (result.Result, expected) {
+    t.Errorf ("Expected %v, got %v!", expected, result.Result)
+  }
+  result = parser (StringToInput ("a42c"))
+  if result.Result != nil || !result.Committed {
+    t.Errorf ("Expected a committed failure, got %v!", result.Result)
+  }
+  result = Sequence () (StringToInput ("a"))
+  if !reflect.DeepEqual (result.Result, []interface{} {}) {
+    t.Errorf ("Expected an empty sequence, got %v!", result.Result)
+  }
+}
+
+func TestExpectCodePointIn (t *testing.T) {
+  var lower = CodePointRange { 'a', 'z' }
+  var digits = CodePointRange { '0', '9' }
+  if ExpectCodePointIn ("[a-z0-9]", lower, digits) (StringToInput ("7")).
+       Result != '7' {
+    t.Errorf ("Expected 7 to be in [a-z0-9]!")
+  }
+  var result = ExpectCodePointIn ("[a-z]", lower) (StringToInput ("A"))
+  if result.Result != nil ||
+     result.Error.Error () != "expected [a-z] at 1:1, found 'A'" {
+    t.Errorf ("Expected A not to be in [a-z], got %v!", result.Error)
+  }
+  if ExpectCodePointNotIn ("[^a-z]", lower) (StringToInput ("A")).
+       Result != 'A' {
+    t.Errorf ("Expected A to be in [^a-z]!")
+  }
+  if ExpectCodePointNotIn ("[^a-z]", lower) (StringToInput ("")).
+       Result != nil {
+    t.Errorf ("Expected [^a-z] to fail at the end of the input!")
+  }
+}
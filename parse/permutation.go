@@ -0,0 +1,121 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+// permutationMember is a parser of a Permutation.
+type permutationMember struct {
+  parser Parser
+  required bool
+
+  // fallback is the result if an optional parser doesn't parse anything.
+  fallback interface{}
+}
+
+// Permutation parses clauses that may appear in any order but at most once
+// each, like the options in "WITH a=1 b=2":
+//
+//   var options = NewPermutation ().
+//     Required (option ("a")).
+//     Optional (option ("b"), int64 (0)).
+//     Parser ()
+//
+// The result is a []interface{} with the results of the parsers in the order
+// of their declaration, no matter in which order they appeared in the input.
+// At every position the parsers that haven't parsed anything yet are tried
+// in the order of their declaration, so the parse takes time quadratic in
+// the number of parsers instead of trying every order.
+type Permutation struct {
+  members []permutationMember
+}
+
+// NewPermutation creates a permutation without parsers.
+func NewPermutation () *Permutation {
+  return &Permutation {}
+}
+
+// Required declares a parser that must succeed exactly once.
+func (permutation *Permutation) Required (parser Parser) *Permutation {
+  permutation.members = append (permutation.members,
+    permutationMember { parser, true, nil })
+  return permutation
+}
+
+// Optional declares a parser that may succeed at most once. If it doesn't
+// then its result is the fallback.
+func (permutation *Permutation) Optional (parser Parser,
+                                          fallback interface{}) *Permutation {
+  permutation.members = append (permutation.members,
+    permutationMember { parser, false, fallback })
+  return permutation
+}
+
+// Parser creates the parser of the permutation. It fails if a required
+// parser is missing and, like OrElse, if one of the parsers fails after
+// committing. Declaring more parsers afterwards doesn't change the parser.
+func (permutation *Permutation) Parser () Parser {
+  var members = append ([]permutationMember {}, permutation.members...)
+  return func (input ParserInput) ParserResult {
+    var results = make ([]interface{}, len (members))
+    var parsed = make ([]bool, len (members))
+    var result = ParserResult { Result: results, RemainingInput: input }
+    for {
+      var oneMoreResult = ParserResult { RemainingInput: result.RemainingInput }
+      var member = -1
+      for i := range members {
+        if parsed[i] {
+          continue
+        }
+        var memberResult = members[i].parser (result.RemainingInput)
+        memberResult.Error = mergeErrors (oneMoreResult.Error,
+                                          memberResult.Error)
+        oneMoreResult = memberResult
+        if memberResult.Result != nil || memberResult.Committed {
+          member = i
+          break
+        }
+      }
+      if oneMoreResult.Result == nil {
+        if oneMoreResult.Committed {
+          return failedSequence (input, result, oneMoreResult)
+        }
+        for i := range members {
+          if !parsed[i] && members[i].required {
+            return failedSequence (input, result, oneMoreResult)
+          }
+        }
+        result.Error = mergeErrors (result.Error, oneMoreResult.Error)
+        break
+      }
+      results[member] = oneMoreResult.Result
+      parsed[member] = true
+      result.RemainingInput = oneMoreResult.RemainingInput
+      result.Error = mergeErrors (result.Error, oneMoreResult.Error)
+      result.Committed = result.Committed || oneMoreResult.Committed
+      result.Recovered = concatErrors (result.Recovered,
+                                       oneMoreResult.Recovered)
+    }
+    for i := range members {
+      if !parsed[i] {
+        results[i] = members[i].fallback
+      }
+    }
+    return result
+  }
+}
@@ -0,0 +1,63 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "fmt"
+  "testing"
+)
+
+// permutationOption parses an option like "a=1" and produces its value.
+func permutationOption (name string) Parser {
+  return Sequence (MaybeSpacesBefore (ExpectString (name)), ExpectString ("="),
+                   ExpectInt64).
+    Convert (func (results interface{}) interface{} {
+      return results.([]interface{})[2]
+    })
+}
+
+func TestPermutation (t *testing.T) {
+  var options = NewPermutation ().
+    Required (permutationOption ("a")).
+    Optional (permutationOption ("b"), int64 (0)).
+    Optional (permutationOption ("c"), int64 (-1)).
+    Parser ()
+  var tests = []parserTest {
+    { options, "a=1", "[1 0 -1]", "", false },
+    { options, "c=3 b=2 a=1", "[1 2 3]", "", false },
+    { options, "b=2 a=1;", "[1 2 -1]", ";", false },
+    { options, "a=1 a=2", "[1 0 -1]", " a=2", false },
+    { options, "b=2", "expected 'a' or 'c' at end of input", "b=2", true },
+    { options, "b=x", "expected integer at 1:3, found 'x'", "b=x", true },
+    { options, "", "expected 'a', 'b' or 'c' at end of input", "", false },
+  }
+  testParsers (t, tests)
+}
+
+func TestEmptyPermutation (t *testing.T) {
+  var permutation = NewPermutation ()
+  var parser = permutation.Parser ()
+  permutation.Required (ExpectString ("x"))
+  var result = parser (StringToInput ("x"))
+  if fmt.Sprint (result.Result) != "[]" || offsetOf (result.RemainingInput) != 0 {
+    t.Errorf ("Expected the empty permutation to parse nothing, got %v!",
+      result.Result)
+  }
+}
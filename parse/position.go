@@ -0,0 +1,177 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "fmt"
+  "sort"
+  "sync"
+  "unicode/utf8"
+)
+
+// Position is a location in the input.
+type Position struct {
+
+  // Offset is the number of code points before this position.
+  Offset int
+
+  // ByteOffset is the number of bytes before this position in the UTF-8
+  // encoding of the input.
+  ByteOffset int
+
+  // Line is the line number, starting with 1.
+  Line int
+
+  // Column is the number of the code point within the line, starting with 1.
+  Column int
+}
+
+// String formats the position as line:column.
+func (position Position) String () string {
+  return fmt.Sprintf ("%d:%d", position.Line, position.Column)
+}
+
+// advance computes the position right after the codePoint that is located at
+// this position and takes up width bytes.
+func (position Position) advance (codePoint rune, width int) Position {
+  if codePoint == '\n' {
+    return Position { position.Offset + 1, position.ByteOffset + width,
+                      position.Line + 1, 1 }
+  }
+  return Position { position.Offset + 1, position.ByteOffset + width,
+                    position.Line, position.Column + 1 }
+}
+
+// startPosition is the position of the first code point of every input.
+var startPosition = Position { 0, 0, 1, 1 }
+
+// runeWidth is the number of bytes of the code point in UTF-8. Invalid code
+// points count as the replacement character that UTF-8 encoders write.
+func runeWidth (codePoint rune) int {
+  var width = utf8.RuneLen (codePoint)
+  if width < 0 {
+    return utf8.RuneLen (utf8.RuneError)
+  }
+  return width
+}
+
+// offsetOf is the offset of the input. It avoids computing the whole
+// position of the inputs that know their offset anyway.
+func offsetOf (input ParserInput) int {
+  switch input := input.(type) {
+  case RuneArrayInput:
+    return input.CurrentPosition
+  case indexedRuneArrayInput:
+    return input.CurrentPosition
+  case MemoInput:
+    return input.offset
+  }
+  return input.Position ().Offset
+}
+
+// GetPosition doesn't consume any input and produces the Position of the
+// input as its result. Use it within Bind or AndThen to find out where
+// things are in the input.
+var GetPosition Parser = func (input ParserInput) ParserResult {
+  return ParserResult { Result: input.Position (), RemainingInput: input }
+}
+
+// Span is the part of the input between the Start and the End position.
+// The End is the position right after the last code point of the span.
+type Span struct {
+
+  // Start is the position of the first code point of the span.
+  Start Position
+
+  // End is the position right after the span.
+  End Position
+}
+
+// Spanned is the result of a parser along with the part of the input that
+// the parser consumed. See WithSpan.
+type Spanned struct {
+
+  // Result is the result of the parser.
+  Result interface{}
+
+  // Span is where the Result came from.
+  Span Span
+}
+
+// WithSpan wraps the result of a successful parse into Spanned so that you
+// know where in the input the result came from. If the parser doesn't consume
+// anything then the span is empty.
+func (parser Parser) WithSpan () Parser {
+  return func (input ParserInput) ParserResult {
+    var result = parser (input)
+    if result.Result != nil {
+      result.Result = Spanned { result.Result,
+                                spanBetween (input, result.RemainingInput) }
+    }
+    return result
+  }
+}
+
+// spanBetween is the span from the input up to the remainingInput.
+func spanBetween (input ParserInput, remainingInput ParserInput) Span {
+  return Span { input.Position (), remainingInput.Position () }
+}
+
+// byteOffsetStride says for how many code points the lineIndex remembers
+// the byte offset: it remembers the byte offset of every 64th code point.
+const byteOffsetStride = 64
+
+// lineIndex remembers where the lines of a text start and some byte offsets
+// so that positions can be computed without scanning the whole text again.
+type lineIndex struct {
+  once sync.Once
+  starts []int
+  byteOffsets []int
+}
+
+// build indexes the text.
+func (index *lineIndex) build (text []rune) {
+  index.starts = []int { 0 }
+  var byteOffset = 0
+  for offset := 0; offset <= len (text); offset++ {
+    if offset % byteOffsetStride == 0 {
+      index.byteOffsets = append (index.byteOffsets, byteOffset)
+    }
+    if offset < len (text) {
+      byteOffset += runeWidth (text[offset])
+      if text[offset] == '\n' {
+        index.starts = append (index.starts, offset + 1)
+      }
+    }
+  }
+}
+
+// position computes the position of the offset in the text.
+func (index *lineIndex) position (text []rune, offset int) Position {
+  index.once.Do (func () { index.build (text) })
+  var line = sort.SearchInts (index.starts, offset + 1) - 1
+  var checkpoint = offset / byteOffsetStride
+  var byteOffset = index.byteOffsets[checkpoint]
+  for _, codePoint := range text[checkpoint * byteOffsetStride:offset] {
+    byteOffset += runeWidth (codePoint)
+  }
+  return Position { offset, byteOffset,
+                    line + 1, offset - index.starts[line] + 1 }
+}
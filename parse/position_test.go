@@ -0,0 +1,98 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "strings"
+  "testing"
+)
+
+func testPosition (t *testing.T, expected Position, actual Position) {
+  if expected != actual {
+    t.Errorf ("Expected the position %#v, got %#v!", expected, actual)
+  }
+}
+
+func TestInputPositions (t *testing.T) {
+  var text = "a熊\n猫b\n" + strings.Repeat ("x", 100) + "大"
+  for _, input := range []ParserInput {
+      StringToInput (text), FileToInput (strings.NewReader (text)),
+      RuneArrayInput { Text: []rune (text) } } {
+    testPosition (t, Position { 0, 0, 1, 1 }, input.Position ())
+    for i := 0; i < 3; i++ {
+      input = input.RemainingInput ()
+    }
+    testPosition (t, Position { 3, 5, 2, 1 }, input.Position ())
+    input = input.RemainingInput ()
+    testPosition (t, Position { 4, 8, 2, 2 }, input.Position ())
+    for i := 0; i < 102; i++ {
+      input = input.RemainingInput ()
+    }
+    if input.CurrentCodePoint () != '大' {
+      t.Errorf ("Expected to be at the last code point!")
+    }
+    testPosition (t, Position { 106, 110, 3, 101 }, input.Position ())
+  }
+}
+
+func TestGetPosition (t *testing.T) {
+  var parser = ExpectIdentifier.AndThen (ExpectSpaces).
+    AndThen (GetPosition).Second ()
+  var result = parser (StringToInput ("ab \n  cd"))
+  testPosition (t, Position { 6, 6, 2, 3 }, result.Result.(Position))
+  if result.RemainingInput.CurrentCodePoint () != 'c' {
+    t.Errorf ("Expected GetPosition not to consume any input!")
+  }
+}
+
+func TestWithSpan (t *testing.T) {
+  var spans = MaybeSpacesBefore (ExpectIdentifier.WithSpan ()).
+    AndThen (MaybeSpacesBefore (ExpectIdentifier.WithSpan ()))
+  var pair = spans (StringToInput ("ab\n  cd")).Result.(Pair)
+  var first = pair.First.(Spanned)
+  var second = pair.Second.(Spanned)
+  if first.Result != "ab" || second.Result != "cd" {
+    t.Errorf ("Expected the spans to contain the identifiers!")
+  }
+  testPosition (t, Position { 0, 0, 1, 1 }, first.Span.Start)
+  testPosition (t, Position { 2, 2, 1, 3 }, first.Span.End)
+  testPosition (t, Position { 5, 5, 2, 3 }, second.Span.Start)
+  testPosition (t, Position { 7, 7, 2, 5 }, second.Span.End)
+}
+
+func TestRuneArrayInputsShareLineIndex (t *testing.T) {
+  var input = RuneArrayInput { Text: []rune ("ab\ncd") }
+  var next = input.RemainingInput ().(indexedRuneArrayInput)
+  var later = next.RemainingInput ().RemainingInput ().(indexedRuneArrayInput)
+  if next.lines != later.lines {
+    t.Errorf ("Expected the remaining inputs to share one line index!")
+  }
+  testPosition (t, Position { 4, 4, 2, 2 },
+    later.RemainingInput ().Position ())
+}
+
+func TestRuneArrayInputOnReusedBuffer (t *testing.T) {
+  var buf = []rune ("a\nbcd")
+  testPosition (t, Position { 4, 4, 2, 3 },
+    RuneArrayInput { Text: buf, CurrentPosition: 4 }.Position ())
+  copy (buf, []rune ("abcde"))
+  testPosition (t, Position { 4, 4, 1, 5 },
+    RuneArrayInput { Text: buf, CurrentPosition: 4 }.Position ())
+}
@@ -0,0 +1,339 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "compress/gzip"
+  "fmt"
+  "io"
+  "sort"
+  "strings"
+  "sync"
+  "text/tabwriter"
+  "time"
+  "unicode/utf8"
+)
+
+// RuleProfile is what a Profile found out about the named parsers with the
+// same name.
+type RuleProfile struct {
+
+  // Name is the name of the parsers.
+  Name string
+
+  // Calls is the number of times the parsers ran.
+  Calls int
+
+  // Successes is the number of successful parses.
+  Successes int
+
+  // Failures is the number of failed parses.
+  Failures int
+
+  // Consumed is the number of code points that the successful parses
+  // consumed.
+  Consumed int
+
+  // Backtracked is the number of code points that the failed parses got
+  // over before they failed, up to the positions of their errors. This
+  // input has to be parsed once more by an alternative.
+  Backtracked int
+
+  // Time is the time spent in the parsers including the named parsers that
+  // they called. Recursive calls are only counted once.
+  Time time.Duration
+
+  // SelfTime is the time spent in the parsers excluding the named parsers
+  // that they called.
+  SelfTime time.Duration
+}
+
+// profileFrame is a named parser that is running.
+type profileFrame struct {
+  name string
+  start time.Time
+
+  // nested is the time spent in the named parsers that this one called.
+  nested time.Duration
+}
+
+// profileSample aggregates the calls with the same stack of named parsers.
+type profileSample struct {
+
+  // stack lists the names of the parsers from the outermost on.
+  stack []string
+  calls int
+  selfTime time.Duration
+}
+
+// Profile measures the named parsers, see StartProfile.
+type Profile struct {
+  rules map[string]*RuleProfile
+
+  // active counts the running parsers of every name, so that recursive
+  // calls don't count twice in the Time.
+  active map[string]int
+
+  stack []profileFrame
+  samples map[string]*profileSample
+  start time.Time
+  duration time.Duration
+  stop func ()
+  lock sync.Mutex
+}
+
+// StartProfile measures how often the named parsers run, how much input they
+// consume or back-track and how long they take, until the profile is
+// stopped. Like tracing, profiling sees the named parsers of all parses in
+// all goroutines, so profile one parse at a time:
+//
+//   var profile = StartProfile ()
+//   var result = Expression (input)
+//   profile.Stop ()
+//   profile.WriteTable (os.Stdout)
+func StartProfile () *Profile {
+  var profile = &Profile { rules: make (map[string]*RuleProfile),
+                           active: make (map[string]int),
+                           samples: make (map[string]*profileSample),
+                           start: time.Now () }
+  profile.stop = observe (profile)
+  return profile
+}
+
+// Stop ends the measurements.
+func (profile *Profile) Stop () {
+  profile.stop ()
+  profile.lock.Lock ()
+  defer profile.lock.Unlock ()
+  if profile.duration == 0 {
+    profile.duration = time.Since (profile.start)
+  }
+}
+
+func (profile *Profile) enter (name string, input ParserInput) {
+  profile.lock.Lock ()
+  defer profile.lock.Unlock ()
+  profile.active[name]++
+  profile.stack = append (profile.stack,
+                          profileFrame { name, time.Now (), 0 })
+}
+
+func (profile *Profile) exit (name string, input ParserInput,
+                              result ParserResult) {
+  var end = time.Now ()
+  profile.lock.Lock ()
+  defer profile.lock.Unlock ()
+  if len (profile.stack) == 0 {
+    return
+  }
+  var frame = profile.stack[len (profile.stack) - 1]
+  profile.stack = profile.stack[:len (profile.stack) - 1]
+  var elapsed = end.Sub (frame.start)
+  if len (profile.stack) > 0 {
+    profile.stack[len (profile.stack) - 1].nested += elapsed
+  }
+  var rule, isKnown = profile.rules[name]
+  if !isKnown {
+    rule = &RuleProfile { Name: name }
+    profile.rules[name] = rule
+  }
+  rule.Calls++
+  if result.Result != nil {
+    rule.Successes++
+    rule.Consumed += offsetOf (result.RemainingInput) - offsetOf (input)
+  } else {
+    rule.Failures++
+    if result.Error != nil && result.Error.Position.Offset > offsetOf (input) {
+      rule.Backtracked += result.Error.Position.Offset - offsetOf (input)
+    }
+  }
+  profile.active[name]--
+  if profile.active[name] == 0 {
+    rule.Time += elapsed
+  }
+  rule.SelfTime += elapsed - frame.nested
+  var stack = make ([]string, len (profile.stack) + 1)
+  for i, caller := range profile.stack {
+    stack[i] = caller.name
+  }
+  stack[len (profile.stack)] = name
+  var key = strings.Join (stack, "\x00")
+  var sample, isSampled = profile.samples[key]
+  if !isSampled {
+    sample = &profileSample { stack: stack }
+    profile.samples[key] = sample
+  }
+  sample.calls++
+  sample.selfTime += elapsed - frame.nested
+}
+
+// Rules returns the measurements of every name, the most time-consuming
+// first.
+func (profile *Profile) Rules () []RuleProfile {
+  profile.lock.Lock ()
+  defer profile.lock.Unlock ()
+  var rules = make ([]RuleProfile, 0, len (profile.rules))
+  for _, rule := range profile.rules {
+    rules = append (rules, *rule)
+  }
+  sort.Slice (rules, func (i int, j int) bool {
+    if rules[i].Time != rules[j].Time {
+      return rules[i].Time > rules[j].Time
+    }
+    return rules[i].Name < rules[j].Name
+  })
+  return rules
+}
+
+// WriteTable writes the measurements as a table with a row per name. The
+// names are aligned to the left and the numbers to the right.
+func (profile *Profile) WriteTable (writer io.Writer) error {
+  var rules = profile.Rules ()
+  // The tabwriter aligns every column to the right, so the names are padded
+  // to the same width beforehand, and the other cells start with the space
+  // between the columns.
+  var width = len ("rule")
+  for _, rule := range rules {
+    if length := utf8.RuneCountInString (rule.Name); length > width {
+      width = length
+    }
+  }
+  var table = tabwriter.NewWriter (writer, 0, 0, 0, ' ', tabwriter.AlignRight)
+  fmt.Fprintf (table, "%-*s\t  calls\t  successes\t  failures\t  consumed\t" +
+    "  backtracked\t  time\t  self time\t\n", width, "rule")
+  for _, rule := range rules {
+    fmt.Fprintf (table, "%-*s\t  %d\t  %d\t  %d\t  %d\t  %d\t  %v\t  %v\t\n",
+      width, rule.Name, rule.Calls, rule.Successes, rule.Failures,
+      rule.Consumed, rule.Backtracked, rule.Time, rule.SelfTime)
+  }
+  return table.Flush ()
+}
+
+// WritePprof writes the measurements in the gzipped protocol buffer format
+// of pprof, so that you can explore them with go tool pprof. Every stack of
+// named parsers is a sample with the number of calls and the time spent.
+func (profile *Profile) WritePprof (writer io.Writer) error {
+  profile.lock.Lock ()
+  var keys = make ([]string, 0, len (profile.samples))
+  for key := range profile.samples {
+    keys = append (keys, key)
+  }
+  sort.Strings (keys)
+  var message protoMessage
+  message.message (1, sampleType (1, 2))
+  message.message (1, sampleType (3, 4))
+  var names []string
+  var functions = make (map[string]uint64)
+  for _, key := range keys {
+    var sample = profile.samples[key]
+    var locations []uint64
+    for i := len (sample.stack) - 1; i >= 0; i-- {
+      var name = sample.stack[i]
+      if _, isKnown := functions[name]; !isKnown {
+        names = append (names, name)
+        functions[name] = uint64 (len (names))
+      }
+      locations = append (locations, functions[name])
+    }
+    var encoded protoMessage
+    encoded.packed (1, locations)
+    encoded.packed (2, []uint64 { uint64 (sample.calls),
+                                  uint64 (sample.selfTime.Nanoseconds ()) })
+    message.message (2, encoded)
+  }
+  var start, duration = profile.start, profile.duration
+  profile.lock.Unlock ()
+  var stringTable = []string { "", "calls", "count", "time", "nanoseconds" }
+  for i, name := range names {
+    var id = uint64 (i + 1)
+    var line, location, function protoMessage
+    line.varint (1, id)
+    location.varint (1, id)
+    location.message (4, line)
+    message.message (4, location)
+    function.varint (1, id)
+    function.varint (2, uint64 (len (stringTable)))
+    function.varint (3, uint64 (len (stringTable)))
+    message.message (5, function)
+    stringTable = append (stringTable, name)
+  }
+  for _, text := range stringTable {
+    message.bytes (6, []byte (text))
+  }
+  message.varint (9, uint64 (start.UnixNano ()))
+  message.varint (10, uint64 (duration.Nanoseconds ()))
+  var compressor = gzip.NewWriter (writer)
+  if _, err := compressor.Write (message); err != nil {
+    return err
+  }
+  return compressor.Close ()
+}
+
+// sampleType describes a value of the pprof samples by the indices of its
+// type and unit in the string table.
+func sampleType (kind int, unit int) protoMessage {
+  var message protoMessage
+  message.varint (1, uint64 (kind))
+  message.varint (2, uint64 (unit))
+  return message
+}
+
+// protoMessage is an encoded protocol buffer message.
+type protoMessage []byte
+
+// tag appends the number of the field and its wire type.
+func (message *protoMessage) tag (field int, wireType int) {
+  message.appendVarint (uint64 (field << 3 | wireType))
+}
+
+func (message *protoMessage) appendVarint (value uint64) {
+  for value >= 0x80 {
+    *message = append (*message, byte (value) | 0x80)
+    value >>= 7
+  }
+  *message = append (*message, byte (value))
+}
+
+// varint appends an integer field.
+func (message *protoMessage) varint (field int, value uint64) {
+  message.tag (field, 0)
+  message.appendVarint (value)
+}
+
+// bytes appends a string or bytes field.
+func (message *protoMessage) bytes (field int, value []byte) {
+  message.tag (field, 2)
+  message.appendVarint (uint64 (len (value)))
+  *message = append (*message, value...)
+}
+
+// message appends an embedded message.
+func (message *protoMessage) message (field int, embedded protoMessage) {
+  message.bytes (field, embedded)
+}
+
+// packed appends a repeated integer field.
+func (message *protoMessage) packed (field int, values []uint64) {
+  var encoded protoMessage
+  for _, value := range values {
+    encoded.appendVarint (value)
+  }
+  message.bytes (field, encoded)
+}
@@ -0,0 +1,120 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "bytes"
+  "compress/gzip"
+  "io"
+  "strings"
+  "testing"
+)
+
+// profiledGrammar back-tracks from key=number to key=identifier.
+func profiledGrammar () Parser {
+  var key = ExpectIdentifier.Named ("Key")
+  var assignment = Try (key.AndThen (ExpectString ("=")).
+    AndThen (ExpectNumber.Named ("Number"))).Named ("NumberAssignment").
+    OrElse (key.AndThen (ExpectString ("=")).
+      AndThen (ExpectIdentifier)).Named ("Assignment")
+  return assignment.AndThen (ExpectString (";")).Repeated ()
+}
+
+func TestProfile (t *testing.T) {
+  var parser = profiledGrammar ()
+  var profile = StartProfile ()
+  parser (StringToInput ("a=1;bc=d;"))
+  profile.Stop ()
+  parser (StringToInput ("a=1;"))
+  var expected = map[string]RuleProfile {
+    "Assignment": { Name: "Assignment", Calls: 3, Successes: 2,
+                    Failures: 1, Consumed: 7 },
+    "NumberAssignment": { Name: "NumberAssignment", Calls: 3, Successes: 1,
+                          Failures: 2, Consumed: 3, Backtracked: 3 },
+    "Key": { Name: "Key", Calls: 5, Successes: 3, Failures: 2,
+             Consumed: 5 },
+    "Number": { Name: "Number", Calls: 2, Successes: 1, Failures: 1,
+                Consumed: 1 },
+  }
+  var rules = profile.Rules ()
+  if len (rules) != len (expected) {
+    t.Fatalf ("Expected %d rules, got %v!", len (expected), rules)
+  }
+  for i, rule := range rules {
+    var measured = rule
+    measured.Time, measured.SelfTime = 0, 0
+    if measured != expected[rule.Name] {
+      t.Errorf ("Expected %+v, got %+v!", expected[rule.Name], measured)
+    }
+    if rule.SelfTime < 0 || rule.SelfTime > rule.Time {
+      t.Errorf ("Expected 0 <= self time <= time, got %v and %v for %s!",
+        rule.SelfTime, rule.Time, rule.Name)
+    }
+    if i > 0 && rules[i - 1].Time < rule.Time {
+      t.Errorf ("Expected the rules to be sorted by time!")
+    }
+  }
+}
+
+func TestProfileTable (t *testing.T) {
+  var profile = StartProfile ()
+  profiledGrammar () (StringToInput ("a=b;"))
+  profile.Stop ()
+  var builder strings.Builder
+  if err := profile.WriteTable (&builder); err != nil {
+    t.Fatalf ("Expected the table to be written, got %v!", err)
+  }
+  var lines = strings.Split (strings.TrimSpace (builder.String ()), "\n")
+  if len (lines) != 5 || strings.Fields (lines[0])[0] != "rule" ||
+     strings.Join (strings.Fields (lines[1])[:6], " ") !=
+       "Assignment 2 1 1 3 0" {
+    t.Errorf ("Expected a header and four rows, got\n%s", builder.String ())
+  }
+  for _, line := range lines {
+    if strings.HasPrefix (line, " ") || strings.HasSuffix (line, " ") {
+      t.Errorf ("Expected the names to be aligned to the left and the " +
+        "numbers to the right, got\n%s",
+        builder.String ())
+      break
+    }
+  }
+}
+
+func TestProfilePprof (t *testing.T) {
+  var profile = StartProfile ()
+  profiledGrammar () (StringToInput ("a=b;"))
+  profile.Stop ()
+  var buffer bytes.Buffer
+  if err := profile.WritePprof (&buffer); err != nil {
+    t.Fatalf ("Expected the profile to be written, got %v!", err)
+  }
+  var reader, err = gzip.NewReader (&buffer)
+  if err != nil {
+    t.Fatalf ("Expected a gzipped profile, got %v!", err)
+  }
+  var message, _ = io.ReadAll (reader)
+  for _, text := range []string { "calls", "nanoseconds", "Assignment",
+                                  "NumberAssignment", "Key" } {
+    if !bytes.Contains (message, append ([]byte { byte (len (text)) },
+                                         text...)) {
+      t.Errorf ("Expected %s in the string table of the profile!", text)
+    }
+  }
+}
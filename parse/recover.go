@@ -0,0 +1,82 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+// ErrorNode is the result of Recover in place of the result of a parser that
+// failed.
+type ErrorNode struct {
+
+  // Error tells why the parser failed.
+  Error *ParseError
+
+  // Span is the part of the input that Recover skipped, including the
+  // synchronization point.
+  Span Span
+}
+
+// Recover applies the parser and, if it fails, records the error and skips
+// the input up to and including the next place where the synchronize parser
+// succeeds, e.g. ExpectString (";"). Instead of failing, Recover then
+// produces an ErrorNode and parsing continues after the synchronization
+// point. The recorded errors end up in the Recovered list of the final
+// ParserResult, so that a single parse can report all errors in the input:
+//
+//   var statements = Recover (statement, ExpectString (";")).Repeated ()
+//
+// If the synchronize parser never succeeds then Recover skips the rest of
+// the input. Use a synchronize parser that consumes input so that
+// repetitions make progress.
+func Recover (parser Parser, synchronize Parser) Parser {
+  return func (input ParserInput) ParserResult {
+    var result = parser (input)
+    if result.Result != nil {
+      return result
+    }
+    var err = result.Error
+    if err == nil {
+      err = Failure (input).Error
+    }
+    var remainingInput = input
+    for !remainingInput.AtEnd () {
+      var synchronized = synchronize (remainingInput)
+      if synchronized.Result != nil {
+        remainingInput = synchronized.RemainingInput
+        break
+      }
+      remainingInput = remainingInput.RemainingInput ()
+    }
+    var node = ErrorNode { err, spanBetween (input, remainingInput) }
+    return ParserResult {
+      Result: node, RemainingInput: remainingInput,
+      Recovered: []*ParseError { err } }
+  }
+}
+
+// concatErrors appends the second list of errors to the first one without
+// modifying either of them.
+func concatErrors (first []*ParseError, second []*ParseError) []*ParseError {
+  if len (first) == 0 {
+    return second
+  }
+  if len (second) == 0 {
+    return first
+  }
+  return append (first[:len (first):len (first)], second...)
+}
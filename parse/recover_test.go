@@ -0,0 +1,93 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "container/list"
+  "strings"
+  "testing"
+)
+
+// statements parses Statement* with
+//
+//   Statement := Identifier "=" Number ";"
+//
+// and recovers from malformed statements at the next ";".
+var statements = Recover (ExpectIdentifier.AndThen (ExpectString ("=")).
+  AndThen (ExpectNumber).AndThen (ExpectString (";")),
+  ExpectString (";")).Repeated ()
+
+func TestRecover (t *testing.T) {
+  var text = "a=1;b=;c=3;d 4;e=5;"
+  for _, input := range []ParserInput {
+      StringToInput (text), FileToInput (strings.NewReader (text)) } {
+    var result = statements (input)
+    if result.Result == nil || !result.RemainingInput.AtEnd () {
+      t.Errorf ("Expected the parser to recover and parse everything!")
+      continue
+    }
+    var results = result.Result.(*list.List)
+    var errorNodes []ErrorNode
+    for element := results.Front (); element != nil; element = element.Next () {
+      if node, isErrorNode := element.Value.(ErrorNode); isErrorNode {
+        errorNodes = append (errorNodes, node)
+      }
+    }
+    if results.Len () != 5 || len (errorNodes) != 2 {
+      t.Errorf ("Expected 5 statements, two of them errors, got %d and %d!",
+        results.Len (), len (errorNodes))
+      continue
+    }
+    if len (result.Recovered) != 2 ||
+       result.Recovered[0] != errorNodes[0].Error ||
+       result.Recovered[1] != errorNodes[1].Error {
+      t.Errorf ("Expected the recovered errors in the order of the input!")
+    }
+    testErrorMessage (t, ParserResult { Error: result.Recovered[0] },
+      "expected number at 1:7, found ';'")
+    testErrorMessage (t, ParserResult { Error: result.Recovered[1] },
+      "expected '=' at 1:13, found ' '")
+    if errorNodes[1].Span.Start.Offset != 11 ||
+       errorNodes[1].Span.End.Offset != 15 {
+      t.Errorf ("Expected the error node to span \"d 4;\", got %v!",
+        errorNodes[1].Span)
+    }
+  }
+}
+
+func TestRecoverWithoutSynchronization (t *testing.T) {
+  var result = statements (StringToInput ("a=1;b"))
+  if result.Result.(*list.List).Len () != 2 ||
+     !result.RemainingInput.AtEnd () || len (result.Recovered) != 1 {
+    t.Errorf ("Expected Recover to skip the rest of the input!")
+  }
+  testErrorMessage (t, ParserResult { Error: result.Recovered[0] },
+    "expected '=' at end of input")
+}
+
+func TestRecoveredErrorsOfAlternatives (t *testing.T) {
+  var parser = Try (statements.AndThen (ExpectString ("!"))).
+    OrElse (statements)
+  var result = parser (StringToInput ("a=;b=1;"))
+  if result.Result == nil || len (result.Recovered) != 1 {
+    t.Errorf ("Expected only the errors of the successful alternative, " +
+      "got %d!", len (result.Recovered))
+  }
+}
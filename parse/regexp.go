@@ -0,0 +1,172 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "io"
+  "regexp"
+  "regexp/syntax"
+  "strconv"
+)
+
+// ExpectRegexp matches the regular expression right at the beginning of the
+// input, see the package regexp for the syntax. The result is a []string
+// with the match followed by the submatches of the capturing groups, like
+// FindStringSubmatch. Groups that don't take part in the match are empty.
+// Like regexp, the first alternative of a|ab that matches wins. The
+// expression reads the input code point by code point, so it works on every
+// input. It panics if the pattern doesn't compile, like regexp.MustCompile.
+//
+// The expression doesn't see the code points before the input, so it can't
+// tell whether the input is at the beginning of a line or of a word. That's
+// why ExpectRegexp also panics if (?m)^, \b or \B can apply at the beginning
+// of the match. Check such conditions with the parsers before it instead.
+// A leading ^ or \A is redundant, and the assertions after the first code
+// point of the match work as usual.
+func ExpectRegexp (pattern string) Parser {
+  var expression = regexp.MustCompile (`\A(?:` + pattern + `)`)
+  if tree, err := syntax.Parse (pattern, syntax.Perl);
+     err == nil && looksBehind (tree) {
+    panic (`parse: ExpectRegexp(` + strconv.Quote (pattern) + `): (?m)^, ` +
+      `\b and \B can't see the code points before the input`)
+  }
+  var description = "match of /" + pattern + "/"
+  return func (input ParserInput) ParserResult {
+    var reader = &inputRuneReader { input, nil, nil }
+    var match = expression.FindReaderSubmatchIndex (reader)
+    if match == nil {
+      return Failure (input, description)
+    }
+    var submatches = make ([]string, len (match) / 2)
+    for i := range submatches {
+      if match[2 * i] >= 0 {
+        submatches[i] = string (reader.codePoints[
+          reader.offset (match[2 * i]):reader.offset (match[2 * i + 1])])
+      }
+    }
+    var remainingInput = input
+    for i := reader.offset (match[1]); i > 0; i-- {
+      remainingInput = remainingInput.RemainingInput ()
+    }
+    return ParserResult { Result: submatches, RemainingInput: remainingInput }
+  }
+}
+
+// looksBehind tells whether the regular expression contains an assertion
+// that depends on the code point before the match and that can apply before
+// the expression reads its first code point. \A and ^ without the flag m
+// always hold there.
+func looksBehind (expression *syntax.Regexp) bool {
+  switch expression.Op {
+  case syntax.OpBeginLine, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+    return true
+  case syntax.OpConcat:
+    for _, sub := range expression.Sub {
+      if looksBehind (sub) {
+        return true
+      }
+      if !matchesEmpty (sub) {
+        return false
+      }
+    }
+    return false
+  }
+  for _, sub := range expression.Sub {
+    if looksBehind (sub) {
+      return true
+    }
+  }
+  return false
+}
+
+// matchesEmpty tells whether the regular expression can match without
+// reading a code point.
+func matchesEmpty (expression *syntax.Regexp) bool {
+  switch expression.Op {
+  case syntax.OpLiteral:
+    return len (expression.Rune) == 0
+  case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL,
+       syntax.OpNoMatch:
+    return false
+  case syntax.OpStar, syntax.OpQuest:
+    return true
+  case syntax.OpRepeat:
+    return expression.Min == 0 || matchesEmpty (expression.Sub[0])
+  case syntax.OpCapture, syntax.OpPlus:
+    return matchesEmpty (expression.Sub[0])
+  case syntax.OpConcat:
+    for _, sub := range expression.Sub {
+      if !matchesEmpty (sub) {
+        return false
+      }
+    }
+    return true
+  case syntax.OpAlternate:
+    for _, sub := range expression.Sub {
+      if matchesEmpty (sub) {
+        return true
+      }
+    }
+    return false
+  }
+  return true
+}
+
+// inputRuneReader lets a regexp read the code points of an input.
+type inputRuneReader struct {
+  input ParserInput
+
+  // codePoints are the code points that have been read so far.
+  codePoints []rune
+
+  // byteOffsets are the offsets of the codePoints in the UTF-8 encoding.
+  byteOffsets []int
+}
+
+// ReadRune reads the next code point of the input.
+func (reader *inputRuneReader) ReadRune () (rune, int, error) {
+  if reader.input.AtEnd () {
+    return 0, 0, io.EOF
+  }
+  var codePoint = reader.input.CurrentCodePoint ()
+  var byteOffset = 0
+  if len (reader.codePoints) > 0 {
+    var last = len (reader.codePoints) - 1
+    byteOffset = reader.byteOffsets[last] + runeWidth (reader.codePoints[last])
+  }
+  reader.codePoints = append (reader.codePoints, codePoint)
+  reader.byteOffsets = append (reader.byteOffsets, byteOffset)
+  reader.input = reader.input.RemainingInput ()
+  return codePoint, runeWidth (codePoint), nil
+}
+
+// offset converts the byte offset into the number of code points before it.
+func (reader *inputRuneReader) offset (byteOffset int) int {
+  var low, high = 0, len (reader.codePoints)
+  for low < high {
+    var middle = (low + high) / 2
+    if reader.byteOffsets[middle] < byteOffset {
+      low = middle + 1
+    } else {
+      high = middle
+    }
+  }
+  return low
+}
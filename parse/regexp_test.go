@@ -0,0 +1,117 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "reflect"
+  "strings"
+  "testing"
+)
+
+func TestExpectRegexp (t *testing.T) {
+  var date = ExpectRegexp (`(\d{4})-(\d{2})-(\d{2})`)
+  var inputs = map[string]func (string) ParserInput {
+    "runes": StringToInput,
+    "UTF-8": UTF8StringToInput,
+    "file": func (text string) ParserInput {
+      return FileToInput (strings.NewReader (text))
+    },
+  }
+  for name, toInput := range inputs {
+    var result = date (toInput ("2018-07-14 and more"))
+    var expected = []string { "2018-07-14", "2018", "07", "14" }
+    if !reflect.DeepEqual (result.Result, expected) {
+      t.Errorf ("Expected %v on the %s input, got %v!",
+        expected, name, result.Result)
+    }
+    if result.RemainingInput.CurrentCodePoint () != ' ' ||
+       offsetOf (result.RemainingInput) != 10 {
+      t.Errorf ("Expected the %s input to continue after the date!", name)
+    }
+  }
+}
+
+func TestExpectRegexpIsAnchored (t *testing.T) {
+  var result = ExpectRegexp (`\d+`) (StringToInput ("x42"))
+  if result.Result != nil {
+    t.Errorf ("Expected no match after the beginning, got %v!", result.Result)
+  }
+  if result.Error == nil || result.Error.Error () !=
+     `expected match of /\d+/ at 1:1, found 'x'` {
+    t.Errorf ("Expected an error at the beginning, got %v!", result.Error)
+  }
+  var lines = ExpectString ("a").AndThen (ExpectRegexp (`(?m)$\n(b)$`))
+  if lines (StringToInput ("a\nb")).Result == nil {
+    t.Errorf ("Expected $ to see the code points after the match!")
+  }
+}
+
+func TestExpectRegexpRejectsLookBehind (t *testing.T) {
+  for _, pattern := range []string {
+      `(?m)^b`, `\bb`, `a|\Bb`, `(x*(?:\b)?y)`, `(?:a?)+\Bb` } {
+    func () {
+      defer func () {
+        if recover () == nil {
+          t.Errorf ("Expected %s to panic!", pattern)
+        }
+      } ()
+      ExpectRegexp (pattern)
+    } ()
+  }
+}
+
+func TestExpectRegexpAcceptsLaterAssertions (t *testing.T) {
+  for pattern, text := range map[string]string {
+      `^b`: "b", `\Ab`: "b", `\d+\b`: "42 x", `[0-9a-f]{8}\b`: "0badf00d.",
+      `a\Bb`: "ab", `(?m)x\n^y`: "x\ny" } {
+    if ExpectRegexp (pattern) (StringToInput (text)).Result == nil {
+      t.Errorf ("Expected %s to match %q!", pattern, text)
+    }
+  }
+  if ExpectRegexp (`\d+\b`) (StringToInput ("42x")).Result != nil {
+    t.Errorf ("Expected \\b to see the code point after the match!")
+  }
+}
+
+func TestExpectRegexpOnUnicode (t *testing.T) {
+  var parser = ExpectRegexp (`(\p{Han}+)(\d)?`).
+    AndThen (ExpectString ("ä"))
+  var result = parser (StringToInput ("熊猫ä"))
+  var expected = []string { "熊猫", "熊猫", "" }
+  if result.Result == nil ||
+     !reflect.DeepEqual (GetFirst (result.Result), expected) {
+    t.Errorf ("Expected %v, got %v and %v!",
+      expected, result.Result, result.Error)
+  }
+  var empty = ExpectRegexp (`x*`) (StringToInput ("熊"))
+  if !reflect.DeepEqual (empty.Result, []string { "" }) ||
+     offsetOf (empty.RemainingInput) != 0 {
+    t.Errorf ("Expected an empty match, got %v!", empty.Result)
+  }
+}
+
+func TestExpectRegexpPanics (t *testing.T) {
+  defer func () {
+    if recover () == nil {
+      t.Errorf ("Expected an invalid pattern to panic!")
+    }
+  } ()
+  ExpectRegexp (`(`)
+}
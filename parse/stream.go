@@ -0,0 +1,218 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "fmt"
+  "io"
+)
+
+// initialStreamBufferSize is the number of code points that a Stream can
+// buffer before it has to discard or grow.
+const initialStreamBufferSize = 4096
+
+// bufferedRune is a code point in the buffer of a Stream.
+type bufferedRune struct {
+  codePoint rune
+  width int
+}
+
+// Stream reads code points into a ring buffer so that huge inputs can be
+// parsed with bounded memory, unlike FileInput which keeps everything that it
+// has read. The parsers may only go back to code points that are marked:
+// the stream keeps the code points from the oldest Mark on and discards the
+// ones before when it needs room. As long as nothing is marked, the stream
+// keeps all code points. Using an input whose code points were discarded
+// panics. Parse line-oriented files one record at a time, marking
+// the start of the next record before releasing the previous mark:
+//
+//   var stream = NewStream (bufio.NewReader (file))
+//   var input = stream.Input ()
+//   var mark = stream.Mark (input)
+//   for !input.AtEnd () {
+//     var result = record (input)
+//     ...
+//     input = result.RemainingInput
+//     var next = stream.Mark (input)
+//     mark.Release ()
+//     mark = next
+//   }
+//   mark.Release ()
+type Stream struct {
+
+  // reader is where the code points come from.
+  reader io.RuneReader
+
+  // buffer is a ring buffer of the code points from the start up to the end.
+  buffer []bufferedRune
+
+  // start is the offset of the oldest code point in the buffer.
+  start int
+
+  // end is the offset right after the newest code point in the buffer.
+  end int
+
+  // atEnd is true once reading from the reader has failed, e.g. at io.EOF.
+  atEnd bool
+
+  // marks counts the marks at every marked offset.
+  marks map[int]int
+}
+
+// NewStream creates a stream that reads from the reader.
+func NewStream (reader io.RuneReader) *Stream {
+  return &Stream { reader, make ([]bufferedRune, initialStreamBufferSize),
+                   0, 0, false, make (map[int]int) }
+}
+
+// Input returns the beginning of the stream. Call it before parsing anything
+// because the beginning may be discarded afterwards.
+func (stream *Stream) Input () ParserInput {
+  return StreamInput { stream, 0, startPosition }
+}
+
+// BufferedCodePoints is the number of code points that the stream currently
+// keeps in memory.
+func (stream *Stream) BufferedCodePoints () int {
+  return stream.end - stream.start
+}
+
+// fill reads the code points up to the offset. It's false if the stream ends
+// before the offset.
+func (stream *Stream) fill (offset int) bool {
+  if offset < stream.start {
+    panic (fmt.Sprintf ("parse: the stream has discarded the offset %d, " +
+      "mark the inputs that you want to parse again", offset))
+  }
+  for stream.end <= offset {
+    if stream.atEnd {
+      return false
+    }
+    var codePoint, width, err = stream.reader.ReadRune ()
+    if err != nil {
+      stream.atEnd = true
+      return false
+    }
+    if stream.end - stream.start == len (stream.buffer) {
+      stream.makeRoom ()
+    }
+    stream.buffer[stream.end % len (stream.buffer)] =
+      bufferedRune { codePoint, width }
+    stream.end++
+  }
+  return true
+}
+
+// makeRoom discards the code points before the oldest mark and grows the
+// buffer if that isn't enough. Without marks it discards nothing because the
+// parsers may go back anywhere, e.g. to the beginning of an alternative.
+func (stream *Stream) makeRoom () {
+  if len (stream.marks) > 0 {
+    stream.start = stream.end
+    for offset := range stream.marks {
+      if offset < stream.start {
+        stream.start = offset
+      }
+    }
+  }
+  if stream.end - stream.start < len (stream.buffer) {
+    return
+  }
+  var buffer = make ([]bufferedRune, 2 * len (stream.buffer))
+  for offset := stream.start; offset < stream.end; offset++ {
+    buffer[offset % len (buffer)] = stream.buffer[offset % len (stream.buffer)]
+  }
+  stream.buffer = buffer
+}
+
+// at returns the buffered code point at the offset.
+func (stream *Stream) at (offset int) bufferedRune {
+  stream.fill (offset)
+  return stream.buffer[offset % len (stream.buffer)]
+}
+
+// Mark is a place in a Stream that the parsers may go back to.
+type Mark struct {
+  stream *Stream
+  offset int
+}
+
+// Mark keeps the code points from the input on in memory until the mark is
+// released. The input may be any input that wraps a StreamInput of this
+// stream, e.g. a MemoInput.
+func (stream *Stream) Mark (input ParserInput) Mark {
+  var offset = offsetOf (input)
+  if offset < stream.start {
+    panic (fmt.Sprintf ("parse: can't mark the discarded offset %d", offset))
+  }
+  stream.marks[offset]++
+  return Mark { stream, offset }
+}
+
+// Release allows the stream to discard the code points that the mark kept.
+// Release every mark exactly once.
+func (mark Mark) Release () {
+  var count, isMarked = mark.stream.marks[mark.offset]
+  if !isMarked {
+    panic ("parse: released a mark twice")
+  }
+  if count == 1 {
+    delete (mark.stream.marks, mark.offset)
+  } else {
+    mark.stream.marks[mark.offset] = count - 1
+  }
+}
+
+// StreamInput is the ParserInput of a Stream.
+type StreamInput struct {
+  stream *Stream
+  offset int
+  position Position
+}
+
+// CurrentCodePoint returns the code point at the beginning of this input.
+func (input StreamInput) CurrentCodePoint () rune {
+  if input.AtEnd () {
+    return EndOfInput
+  }
+  return input.stream.at (input.offset).codePoint
+}
+
+// RemainingInput is the input after the current code point.
+func (input StreamInput) RemainingInput () ParserInput {
+  if input.AtEnd () {
+    return input
+  }
+  var current = input.stream.at (input.offset)
+  return StreamInput { input.stream, input.offset + 1,
+                       input.position.advance (current.codePoint,
+                                               current.width) }
+}
+
+// Position returns the position of the current code point.
+func (input StreamInput) Position () Position {
+  return input.position
+}
+
+// AtEnd reads the current code point if necessary and tells whether the
+// stream has ended before it.
+func (input StreamInput) AtEnd () bool {
+  return !input.stream.fill (input.offset)
+}
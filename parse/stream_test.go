@@ -0,0 +1,137 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "io"
+  "strings"
+  "testing"
+)
+
+// repeatedLines produces the line count times without keeping all of them
+// in memory.
+type repeatedLines struct {
+  line []rune
+  count int
+  offset int
+}
+
+func (lines *repeatedLines) ReadRune () (rune, int, error) {
+  if lines.offset == len (lines.line) * lines.count {
+    return 0, 0, io.EOF
+  }
+  var codePoint = lines.line[lines.offset % len (lines.line)]
+  lines.offset++
+  return codePoint, runeWidth (codePoint), nil
+}
+
+// record parses key=value lines. Both alternatives start with the key so
+// that the parser has to go back to the beginning of the record.
+var record = Try (ExpectIdentifier.AndThen (ExpectString ("=")).
+  AndThen (ExpectNumber).AndThen (ExpectString ("\n"))).
+  OrElse (ExpectIdentifier.AndThen (ExpectString ("=")).
+    AndThen (ExpectIdentifier).AndThen (ExpectString ("\n")))
+
+func TestStreamWithBoundedMemory (t *testing.T) {
+  var stream = NewStream (&repeatedLines { []rune ("key=value\n"), 100000, 0 })
+  var input = stream.Input ()
+  var mark = stream.Mark (input)
+  var records = 0
+  for !input.AtEnd () {
+    var result = record (input)
+    if result.Result == nil {
+      t.Fatalf ("Expected the record to be parsed, got %s!", result.Error)
+    }
+    records++
+    input = result.RemainingInput
+    var next = stream.Mark (input)
+    mark.Release ()
+    mark = next
+    if stream.BufferedCodePoints () > initialStreamBufferSize {
+      t.Fatalf ("Expected the stream to keep at most %d code points, " +
+        "got %d!", initialStreamBufferSize, stream.BufferedCodePoints ())
+    }
+  }
+  mark.Release ()
+  if records != 100000 {
+    t.Errorf ("Expected 100000 records, got %d!", records)
+  }
+}
+
+func TestStreamPositions (t *testing.T) {
+  var text = "ab\nä€\ncd"
+  var expected = StringToInput (text)
+  var input = NewStream (strings.NewReader (text)).Input ()
+  for ; !input.AtEnd (); input = input.RemainingInput () {
+    if input.CurrentCodePoint () != expected.CurrentCodePoint () ||
+       input.Position () != expected.Position () {
+      t.Errorf ("Expected %c at %v, got %c at %v!",
+        expected.CurrentCodePoint (), expected.Position (),
+        input.CurrentCodePoint (), input.Position ())
+    }
+    expected = expected.RemainingInput ()
+  }
+  if !expected.AtEnd () || input.Position () != expected.Position () {
+    t.Errorf ("Expected the stream to end together with the runes!")
+  }
+}
+
+func TestStreamGrowsForMarks (t *testing.T) {
+  var text = strings.Repeat ("a", 3 * initialStreamBufferSize) + "b"
+  var stream = NewStream (strings.NewReader (text))
+  var input = stream.Input ()
+  var mark = stream.Mark (input)
+  var as = ExpectString ("a").Repeated ()
+  var parser = Try (as.AndThen (ExpectString ("c"))).
+    OrElse (as.AndThen (ExpectString ("b")))
+  if parser (input).Result == nil {
+    t.Errorf ("Expected the marked input to survive back-tracking!")
+  }
+  mark.Release ()
+}
+
+func TestStreamKeepsUnmarkedInput (t *testing.T) {
+  var prefix = strings.Repeat ("a", initialStreamBufferSize - 2)
+  var stream = NewStream (strings.NewReader (prefix + "abcX"))
+  var parser = ExpectString (prefix).AndThen (ExpectString ("abcd"))
+  var result = parser (stream.Input ())
+  if result.Result != nil || result.Error == nil {
+    t.Errorf ("Expected the parser to fail with an error!")
+  }
+}
+
+func TestStreamPanicsOnDiscardedInput (t *testing.T) {
+  var text = strings.Repeat ("a", initialStreamBufferSize / 2) +
+    strings.Repeat ("b", 2 * initialStreamBufferSize)
+  var stream = NewStream (strings.NewReader (text))
+  var input = stream.Input ()
+  var mark = stream.Mark (input)
+  var as = ExpectString ("a").Repeated () (input)
+  var next = stream.Mark (as.RemainingInput)
+  mark.Release ()
+  ExpectString ("b").Repeated () (as.RemainingInput)
+  next.Release ()
+  defer func () {
+    if recover () == nil {
+      t.Errorf ("Expected the released input to panic!")
+    }
+  } ()
+  input.CurrentCodePoint ()
+}
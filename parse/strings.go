@@ -0,0 +1,253 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "strings"
+  "unicode/utf16"
+  "unicode/utf8"
+)
+
+// StringDialect selects the quotes and the escape sequences of the string
+// literals that ExpectStringLiteral parses.
+type StringDialect int
+
+const (
+
+  // GoString is a Go string literal in double quotes like "a\tb\u00e9".
+  // It supports the escapes \a \b \f \n \r \t \v \\ \", the bytes \xff and
+  // \377 and the code points \u00e9 and \U0001F600. It can't span lines.
+  GoString StringDialect = iota
+
+  // JSONString is a JSON string like "a\tb\u00e9". It supports the escapes
+  // \b \f \n \r \t \/ \\ \" and \u00e9, where surrogate pairs like
+  // \ud83d\ude00 make up one code point. Control characters have to be
+  // escaped.
+  JSONString
+
+  // SingleQuotedString is a string in single quotes like 'it\'s'. It
+  // supports the escapes \b \f \n \r \t \\ \' \" and \u00e9 like JSON does.
+  // It can't span lines.
+  SingleQuotedString
+
+  // RawString is a string in back quotes like Go's `C:\raw`. It has no
+  // escapes and may span lines.
+  RawString
+
+  // TripleQuotedString is a string in triple double quotes like """a "b" c""".
+  // It has no escapes, may span lines and ends at the first """.
+  TripleQuotedString
+)
+
+// stringSyntax describes the string literals of a StringDialect.
+type stringSyntax struct {
+  description string
+
+  // quote opens and closes the literal.
+  quote string
+
+  // escapes maps the code points after a backslash to their meaning. There
+  // are no escape sequences if it's nil.
+  escapes map[rune]string
+
+  // byteEscapes allows \xff and \377.
+  byteEscapes bool
+
+  // longUnicode allows \U0001F600.
+  longUnicode bool
+
+  // surrogatePairs combines \ud83d\ude00 into one code point. Otherwise
+  // surrogates are invalid code points.
+  surrogatePairs bool
+
+  multiline bool
+
+  // controlCharacters allows control characters without escapes.
+  controlCharacters bool
+}
+
+// stringSyntaxes are the syntaxes of the StringDialects.
+var stringSyntaxes = map[StringDialect]stringSyntax {
+  GoString: { "string literal", "\"",
+    map[rune]string { 'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r",
+                      't': "\t", 'v': "\v", '\\': "\\", '"': "\"" },
+    true, true, false, false, true },
+  JSONString: { "JSON string", "\"",
+    map[rune]string { 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t",
+                      '/': "/", '\\': "\\", '"': "\"" },
+    false, false, true, false, false },
+  SingleQuotedString: { "single-quoted string", "'",
+    map[rune]string { 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t",
+                      '\\': "\\", '\'': "'", '"': "\"" },
+    false, false, true, false, true },
+  RawString: { "raw string", "`", nil, false, false, false, true, true },
+  TripleQuotedString: { "triple-quoted string", "\"\"\"", nil,
+    false, false, false, true, true },
+}
+
+// ExpectStringLiteral parses a string literal of the dialect and the result
+// will be its contents with the escape sequences replaced. Once the opening
+// quote is read, a missing closing quote or an invalid escape sequence is
+// a committed failure that points to the problem.
+func ExpectStringLiteral (dialect StringDialect) Parser {
+  var syntax, isKnown = stringSyntaxes[dialect]
+  if !isKnown {
+    panic ("parse: unknown string dialect")
+  }
+  return func (input ParserInput) ParserResult {
+    var remaining, isQuoted = skipText (input, syntax.quote)
+    if !isQuoted {
+      return Failure (input, syntax.description)
+    }
+    var builder strings.Builder
+    for {
+      if end, isClosed := skipText (remaining, syntax.quote); isClosed {
+        return ParserResult { Result: builder.String (), RemainingInput: end }
+      }
+      var codePoint = remaining.CurrentCodePoint ()
+      if remaining.AtEnd () ||
+         codePoint == '\n' && !syntax.multiline ||
+         codePoint < ' ' && codePoint != '\n' && !syntax.controlCharacters {
+        return ParserResult {
+          RemainingInput: input,
+          Error: newParseError (remaining, 1,
+                                []string { quote (syntax.quote) }),
+          Committed: true }
+      }
+      if codePoint == '\\' && syntax.escapes != nil {
+        var next, err = syntax.unescape (remaining, &builder)
+        if err != nil {
+          return ParserResult {
+            RemainingInput: input, Error: err, Committed: true }
+        }
+        remaining = next
+        continue
+      }
+      builder.WriteRune (codePoint)
+      remaining = remaining.RemainingInput ()
+    }
+  }
+}
+
+// skipText returns the input after the text if the input starts with it.
+func skipText (input ParserInput, text string) (ParserInput, bool) {
+  for _, codePoint := range text {
+    if input.AtEnd () || input.CurrentCodePoint () != codePoint {
+      return input, false
+    }
+    input = input.RemainingInput ()
+  }
+  return input, true
+}
+
+// unescape writes the meaning of the escape sequence at the beginning of the
+// input to the builder and returns the input after the escape sequence.
+func (syntax stringSyntax) unescape (input ParserInput,
+                                     builder *strings.Builder) (
+                                       ParserInput, *ParseError) {
+  var escape = input.RemainingInput ()
+  var letter = escape.CurrentCodePoint ()
+  if meaning, isSimple := syntax.escapes[letter]; isSimple {
+    builder.WriteString (meaning)
+    return escape.RemainingInput (), nil
+  }
+  switch {
+  case letter == 'u' || letter == 'U' && syntax.longUnicode:
+    var length = 4
+    if letter == 'U' {
+      length = 8
+    }
+    var value, remaining, err = scanDigitsOf (escape.RemainingInput (),
+                                              length, 16)
+    if err != nil {
+      return nil, err
+    }
+    var codePoint = rune (value)
+    if syntax.surrogatePairs && utf16.IsSurrogate (codePoint) {
+      return syntax.unescapeSurrogates (codePoint, remaining, builder)
+    }
+    if !utf8.ValidRune (codePoint) {
+      return nil, newParseError (input, length + 2,
+                                 []string { "valid code point" })
+    }
+    builder.WriteRune (codePoint)
+    return remaining, nil
+  case letter == 'x' && syntax.byteEscapes:
+    var value, remaining, err = scanDigitsOf (escape.RemainingInput (), 2, 16)
+    if err != nil {
+      return nil, err
+    }
+    builder.WriteByte (byte (value))
+    return remaining, nil
+  case '0' <= letter && letter <= '7' && syntax.byteEscapes:
+    var value, remaining, err = scanDigitsOf (escape, 3, 8)
+    if err != nil {
+      return nil, err
+    }
+    if value > 255 {
+      return nil, newParseError (input, 4, []string { "octal byte value" })
+    }
+    builder.WriteByte (byte (value))
+    return remaining, nil
+  }
+  return nil, newParseError (input, 2, []string { "escape sequence" })
+}
+
+// unescapeSurrogates writes the code point of the surrogate pair that starts
+// with the surrogate, which has already been read, to the builder. If there's
+// no valid pair then the surrogate is replaced by U+FFFD like encoding/json
+// does.
+func (syntax stringSyntax) unescapeSurrogates (surrogate rune,
+                                               input ParserInput,
+                                               builder *strings.Builder) (
+                                                 ParserInput, *ParseError) {
+  if next, isEscape := skipText (input, "\\u"); isEscape {
+    var value, remaining, err = scanDigitsOf (next, 4, 16)
+    if err != nil {
+      return nil, err
+    }
+    var codePoint = utf16.DecodeRune (surrogate, rune (value))
+    if codePoint != utf8.RuneError {
+      builder.WriteRune (codePoint)
+      return remaining, nil
+    }
+  }
+  builder.WriteRune (utf8.RuneError)
+  return input, nil
+}
+
+// scanDigitsOf reads exactly length digits of the base and returns their
+// value along with the remaining input.
+func scanDigitsOf (input ParserInput, length int, base int) (
+                     int, ParserInput, *ParseError) {
+  var value = 0
+  for i := 0; i < length; i++ {
+    if !isDigitOf (input, base) {
+      var description = "hexadecimal digit"
+      if base == 8 {
+        description = "octal digit"
+      }
+      return 0, nil, newParseError (input, 1, []string { description })
+    }
+    value = value * base + digitValue (input.CurrentCodePoint ())
+    input = input.RemainingInput ()
+  }
+  return value, input, nil
+}
@@ -0,0 +1,105 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestExpectStringLiteral (t *testing.T) {
+  var tests = []struct {
+    dialect StringDialect
+    input string
+    expected string
+    rest string
+  } {
+    { GoString, `"a\tb\\c\"d" e`, "a\tb\\c\"d", " e" },
+    { GoString, `"\u00e9\U0001F600\x41\101\a"`, "é😀AA\a", "" },
+    { GoString, `"\xff"`, "\xff", "" },
+    { GoString, `"熊猫"`, "熊猫", "" },
+    { GoString, `""""`, "", `""` },
+    { JSONString, `"\/\u00e9\ud83d\ude00\n"`, "/é😀\n", "" },
+    { JSONString, `"\ud83dx"`, "\uFFFDx", "" },
+    { JSONString, `"\ud83d\u0041"`, "\uFFFDA", "" },
+    { SingleQuotedString, `'it\'s "ok"'`, "it's \"ok\"", "" },
+    { RawString, "`C:\\raw\n`x", "C:\\raw\n", "x" },
+    { TripleQuotedString, `"""a "b" ""c"""x`, "a \"b\" \"\"c", "x" },
+  }
+  for _, test := range tests {
+    var result = ExpectStringLiteral (test.dialect) (StringToInput (test.input))
+    if result.Result != test.expected {
+      t.Errorf ("Expected %s to be %q, got %q and %v!",
+        test.input, test.expected, result.Result, result.Error)
+      continue
+    }
+    if rest := string ([]rune (test.input)[offsetOf (result.RemainingInput):]);
+       rest != test.rest {
+      t.Errorf ("Expected %s to remain of %s, got %s!",
+        test.rest, test.input, rest)
+    }
+  }
+}
+
+func TestStringLiteralErrors (t *testing.T) {
+  var tests = []struct {
+    dialect StringDialect
+    input string
+    expected string
+    committed bool
+  } {
+    { GoString, "x", "expected string literal at 1:1, found 'x'", false },
+    { JSONString, "'a'", "expected JSON string at 1:1, found '\\''", false },
+    { GoString, `"a\qb"`, "expected escape sequence at 1:3, found '\\\\q'",
+      true },
+    { GoString, `"\'"`, "expected escape sequence at 1:2, found '\\\\\\''",
+      true },
+    { JSONString, `"\x41"`, "expected escape sequence at 1:2, " +
+      "found '\\\\x'", true },
+    { GoString, `"\u12g4"`, "expected hexadecimal digit at 1:6, found 'g'",
+      true },
+    { GoString, `"\ud800"`, "expected valid code point at 1:2, " +
+      "found '\\\\ud800'", true },
+    { GoString, `"\400"`, "expected octal byte value at 1:2, " +
+      "found '\\\\400'", true },
+    { GoString, `"\18"`, "expected octal digit at 1:4, found '8'", true },
+    { GoString, "\"ab", "expected '\"' at end of input", true },
+    { GoString, "\"a\nb\"", "expected '\"' at 1:3, found '\\n'", true },
+    { JSONString, "\"a\tb\"", "expected '\"' at 1:3, found '\\t'", true },
+    { TripleQuotedString, `""""`, "expected '\"\"\"' at end of input", true },
+  }
+  for _, test := range tests {
+    var result = ExpectStringLiteral (test.dialect) (StringToInput (test.input))
+    if result.Result != nil || result.Committed != test.committed ||
+       result.Error == nil || result.Error.Error () != test.expected {
+      t.Errorf ("Expected the error %s for %s, got %v and %v!",
+        test.expected, test.input, result.Result, result.Error)
+    }
+  }
+}
+
+func TestStringLiteralOnFiles (t *testing.T) {
+  var input = FileToInput (strings.NewReader (`'a\u00e9\n' rest`))
+  var result = ExpectStringLiteral (SingleQuotedString) (input)
+  if result.Result != "aé\n" || result.RemainingInput.Position ().Offset != 11 {
+    t.Errorf ("Expected the literal to be read from the file, got %q at %v!",
+      result.Result, result.RemainingInput.Position ())
+  }
+}
@@ -0,0 +1,238 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "strings"
+  "unicode/utf8"
+)
+
+// Token is a piece of the input that a lexer recognized, e.g. a keyword,
+// an identifier or a number.
+type Token struct {
+
+  // Kind is the name of the lexer rule that produced the token.
+  Kind string
+
+  // Text is the part of the input that makes up the token.
+  Text string
+
+  // Span is where the token is located in the input.
+  Span Span
+}
+
+// TokenInput is a sequence of tokens that you can parse with the same
+// combinators as code points. Use ExpectToken and ExpectTokenText to parse
+// tokens. Positions refer to the original input of the lexer, so errors
+// still tell the line and column in the source text. The end of the input
+// is located at the end of the last token.
+type TokenInput struct {
+
+  // Tokens are all tokens of the input. Please keep them unchanged while
+  // parsers are working on them.
+  Tokens []Token
+
+  // CurrentPosition points to the current token in the Tokens
+  CurrentPosition int
+}
+
+// CurrentToken returns the token at the beginning of this input. At the end
+// of the input it's an empty token without a kind.
+func (input TokenInput) CurrentToken () Token {
+  if input.AtEnd () {
+    var end = startPosition
+    if len (input.Tokens) > 0 {
+      end = input.Tokens[len (input.Tokens) - 1].Span.End
+    }
+    return Token { "", "", Span { end, end } }
+  }
+  return input.Tokens[input.CurrentPosition]
+}
+
+// CurrentCodePoint returns the first code point of the current token. Don't
+// use code point parsers on tokens, use ExpectToken instead.
+func (input TokenInput) CurrentCodePoint () rune {
+  if input.AtEnd () {
+    return EndOfInput
+  }
+  var codePoint, _ = utf8.DecodeRuneInString (input.CurrentToken ().Text)
+  return codePoint
+}
+
+// RemainingInput returns the tokens after the current token.
+func (input TokenInput) RemainingInput () ParserInput {
+  if input.AtEnd () {
+    return input
+  }
+  return TokenInput { input.Tokens, input.CurrentPosition + 1 }
+}
+
+// Position returns the position of the current token in the original input.
+func (input TokenInput) Position () Position {
+  return input.CurrentToken ().Span.Start
+}
+
+// AtEnd is true iff there are no tokens left.
+func (input TokenInput) AtEnd () bool {
+  return input.CurrentPosition >= len (input.Tokens)
+}
+
+// TokensToInput converts the tokens to a TokenInput so you can use parsers
+// on them.
+func TokensToInput (tokens []Token) ParserInput {
+  return TokenInput { tokens, 0 }
+}
+
+// ExpectToken parses one token of the kind. The token is the result.
+func ExpectToken (kind string) Parser {
+  return func (input ParserInput) ParserResult {
+    if tokens, isTokenInput := underlying (input).(TokenInput); isTokenInput &&
+       !tokens.AtEnd () && tokens.CurrentToken ().Kind == kind {
+      return ParserResult {
+        Result: tokens.CurrentToken (),
+        RemainingInput: input.RemainingInput () }
+    }
+    return Failure (input, kind)
+  }
+}
+
+// ExpectTokenText parses one token of the kind that consists of the text,
+// e.g. a certain keyword. The token is the result.
+func ExpectTokenText (kind string, text string) Parser {
+  return ExpectToken (kind).Convert (func (result interface{}) interface{} {
+    if result.(Token).Text != text {
+      return nil
+    }
+    return result
+  }).Expecting (quote (text))
+}
+
+// LexerRule tells the lexer how to recognize one kind of token.
+type LexerRule struct {
+
+  // Kind is the kind of the tokens.
+  Kind string
+
+  // Parser recognizes the tokens. Its result doesn't matter, the text of the
+  // token is whatever the parser consumed.
+  Parser Parser
+
+  // Skip makes the lexer drop the tokens, e.g. spaces and comments.
+  Skip bool
+}
+
+// TokenRule is a lexer rule for tokens of the kind.
+func TokenRule (kind string, parser Parser) LexerRule {
+  return LexerRule { kind, parser, false }
+}
+
+// SkipRule is a lexer rule for input that's not part of any token.
+func SkipRule (parser Parser) LexerRule {
+  return LexerRule { "", parser, true }
+}
+
+// Lexer splits the input into tokens. It tries the rules in the given order
+// at every position and the first rule that consumes some input wins, just
+// like OrElse. Its result is a []Token that you can turn into an input with
+// TokensToInput. The lexer fails if no rule matches at some position, unless
+// the error of one of the rules is further in the input, e.g. at the end of
+// an unterminated string literal.
+//
+//   var lexer = Lexer (
+//     SkipRule (ExpectSpaces),
+//     TokenRule ("keyword",
+//       ExpectString ("AND").OrElse (ExpectString ("OR"))),
+//     TokenRule ("identifier", ExpectIdentifier),
+//     TokenRule ("symbol", ExpectString ("(").OrElse (ExpectString (")"))))
+//
+// Mind that the order matters: here "ANDY" becomes the keyword AND followed
+// by the identifier Y. Use a more specific parser for keywords if you don't
+// want that.
+func Lexer (rules ...LexerRule) Parser {
+  return func (input ParserInput) ParserResult {
+    var tokens, remainingInput, failure = splitTokens (rules, input, nil)
+    if failure != nil {
+      return ParserResult {
+        RemainingInput: input, Error: failure,
+        Committed: consumed (input, remainingInput) }
+    }
+    return ParserResult { Result: tokens, RemainingInput: remainingInput }
+  }
+}
+
+// splitTokens splits the input into tokens like Lexer. It stops early on the
+// first remaining input for which stop is true, unless stop is nil. If no
+// rule matches at some position then it returns the tokens before that
+// position, the input at that position and the error.
+func splitTokens (rules []LexerRule, input ParserInput,
+                  stop func (ParserInput) bool) ([]Token, ParserInput,
+                                                 *ParseError) {
+  var tokens = []Token {}
+  var err *ParseError
+  var remainingInput = input
+  for !remainingInput.AtEnd () && (stop == nil || !stop (remainingInput)) {
+    var matched = false
+    for _, rule := range rules {
+      var result = rule.Parser (remainingInput)
+      err = mergeErrors (err, result.Error)
+      if result.Result == nil ||
+         !consumed (remainingInput, result.RemainingInput) {
+        continue
+      }
+      if !rule.Skip {
+        tokens = append (tokens, Token { rule.Kind,
+          textBetween (remainingInput, result.RemainingInput),
+          spanBetween (remainingInput, result.RemainingInput) })
+      }
+      remainingInput = result.RemainingInput
+      matched = true
+      break
+    }
+    if !matched {
+      var kinds []string
+      for _, rule := range rules {
+        if !rule.Skip {
+          kinds = append (kinds, rule.Kind)
+        }
+      }
+      var failure = Failure (remainingInput, kinds...).Error
+      if err != nil && failure.isBefore (err) {
+        failure = err
+      }
+      return tokens, remainingInput, failure
+    }
+  }
+  return tokens, remainingInput, nil
+}
+
+// textBetween is the text from the input up to the remainingInput.
+func textBetween (input ParserInput, remainingInput ParserInput) string {
+  if substrings, hasSubstrings := underlying (input).(interface {
+      Substring (ParserInput) string }); hasSubstrings {
+    return substrings.Substring (underlying (remainingInput))
+  }
+  var builder strings.Builder
+  var end = offsetOf (remainingInput)
+  for offsetOf (input) < end {
+    builder.WriteRune (input.CurrentCodePoint ())
+    input = input.RemainingInput ()
+  }
+  return builder.String ()
+}
@@ -0,0 +1,86 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "fmt"
+  "strings"
+  "testing"
+)
+
+var testLexer = Lexer (
+  SkipRule (ExpectSpaces),
+  TokenRule ("identifier", ExpectIdentifier),
+  TokenRule ("number", ExpectNumber),
+  TokenRule ("symbol", ExpectString ("=").OrElse (ExpectString (";"))))
+
+func lex (t *testing.T, text string) ParserInput {
+  var result = testLexer (FileToInput (strings.NewReader (text)))
+  if result.Result == nil {
+    t.Fatalf ("Expected the lexer to succeed, got %s!", result.Error)
+  }
+  return TokensToInput (result.Result.([]Token))
+}
+
+func TestLexer (t *testing.T) {
+  var tokens = lex (t, "x = 42;\n  yz=1;").(TokenInput).Tokens
+  var expected = []string {
+    "identifier x 1:1-1:2", "symbol = 1:3-1:4", "number 42 1:5-1:7",
+    "symbol ; 1:7-1:8", "identifier yz 2:3-2:5", "symbol = 2:5-2:6",
+    "number 1 2:6-2:7", "symbol ; 2:7-2:8",
+  }
+  if len (tokens) != len (expected) {
+    t.Fatalf ("Expected %d tokens, got %v!", len (expected), tokens)
+  }
+  for i, token := range tokens {
+    var description = fmt.Sprintf ("%s %s %s-%s", token.Kind, token.Text,
+      token.Span.Start, token.Span.End)
+    if description != expected[i] {
+      t.Errorf ("Expected the token %s, got %s!", expected[i], description)
+    }
+  }
+  if tokens[4].Span.Start.Offset != 10 || tokens[4].Span.End.Offset != 12 {
+    t.Errorf ("Expected the offsets 10 and 12, got %v!", tokens[4].Span)
+  }
+}
+
+func TestLexerError (t *testing.T) {
+  var result = testLexer (StringToInput ("x = ?"))
+  if result.Result != nil {
+    t.Errorf ("Expected the lexer to fail!")
+  }
+  testErrorMessage (t, result,
+    "expected identifier, number or symbol at 1:5, found '?'")
+}
+
+func TestExpectToken (t *testing.T) {
+  var assignment = ExpectToken ("identifier").
+    AndThen (ExpectTokenText ("symbol", "=")).
+    AndThen (ExpectToken ("number")).
+    AndThen (ExpectTokenText ("symbol", ";")).OnceOrMore ()
+  var result = assignment (lex (t, "x = 42;\n  yz=1;"))
+  if result.Result == nil || !result.RemainingInput.AtEnd () {
+    t.Errorf ("Expected the parser to parse all tokens!")
+  }
+  result = assignment (lex (t, "x = 42;\n  yz 1;"))
+  testErrorMessage (t, result, "expected '=' at 2:6, found '1'")
+  result = assignment (lex (t, "x = 42;\n  yz ="))
+  testErrorMessage (t, result, "expected number at end of input")
+}
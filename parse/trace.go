@@ -0,0 +1,208 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "fmt"
+  "io"
+  "strings"
+  "sync"
+  "sync/atomic"
+)
+
+// namedParserObserver is notified whenever a named parser runs.
+type namedParserObserver interface {
+
+  // enter is called before the named parser parses the input.
+  enter (name string, input ParserInput)
+
+  // exit is called after the named parser has parsed the input.
+  exit (name string, input ParserInput, result ParserResult)
+}
+
+// observers holds the []namedParserObserver that are currently active. The
+// slice is replaced, never modified, so that named parsers can read it
+// without locking.
+var observers atomic.Value
+
+// observersLock serializes the replacements of the observers.
+var observersLock sync.Mutex
+
+// observe notifies the observer about all named parsers until the returned
+// function is called.
+func observe (observer namedParserObserver) func () {
+  observersLock.Lock ()
+  defer observersLock.Unlock ()
+  var active, _ = observers.Load ().([]namedParserObserver)
+  observers.Store (append (active[:len (active):len (active)], observer))
+  var once sync.Once
+  return func () {
+    once.Do (func () {
+      observersLock.Lock ()
+      defer observersLock.Unlock ()
+      var remaining []namedParserObserver
+      for _, other := range observers.Load ().([]namedParserObserver) {
+        if other != observer {
+          remaining = append (remaining, other)
+        }
+      }
+      observers.Store (remaining)
+    })
+  }
+}
+
+// Named gives the parser a name that shows up when tracing, see Trace.
+// Otherwise the named parser works just like the parser. Use Expecting to
+// name the parser in error messages.
+func (parser Parser) Named (name string) Parser {
+  return func (input ParserInput) ParserResult {
+    var active, _ = observers.Load ().([]namedParserObserver)
+    if len (active) == 0 {
+      return parser (input)
+    }
+    for _, observer := range active {
+      observer.enter (name, input)
+    }
+    // A panic exits the parser with a failure without an error, so that
+    // the observers still see a parser exit for every parser they saw enter.
+    var result ParserResult
+    defer func () {
+      for i := len (active) - 1; i >= 0; i-- {
+        active[i].exit (name, input, result)
+      }
+    } ()
+    result = parser (input)
+    return result
+  }
+}
+
+// TraceEventKind tells what happened to a named parser.
+type TraceEventKind int
+
+const (
+
+  // TraceEnter means that the named parser starts parsing.
+  TraceEnter TraceEventKind = iota
+
+  // TraceSuccess means that the named parser succeeded.
+  TraceSuccess
+
+  // TraceFailure means that the named parser failed.
+  TraceFailure
+)
+
+// String returns enter, success or failure.
+func (kind TraceEventKind) String () string {
+  switch kind {
+  case TraceEnter:
+    return "enter"
+  case TraceSuccess:
+    return "success"
+  }
+  return "failure"
+}
+
+// TraceEvent is something that happened to a named parser.
+type TraceEvent struct {
+
+  // Kind tells whether the parser started, succeeded or failed.
+  Kind TraceEventKind
+
+  // Name is the name of the parser.
+  Name string
+
+  // Depth is the number of named parsers that are running around this one.
+  Depth int
+
+  // Start is where the parser started.
+  Start Position
+
+  // End is where a successful parser stopped. Otherwise it's the Start.
+  End Position
+
+  // Error is the error of a failure.
+  Error *ParseError
+}
+
+// String formats the event like "Atom success 1:3-1:5", indented by two
+// spaces per depth.
+func (event TraceEvent) String () string {
+  var text = strings.Repeat ("  ", event.Depth) + event.Name + " " +
+    event.Kind.String () + " " + event.Start.String ()
+  if event.Kind == TraceSuccess {
+    text += "-" + event.End.String ()
+  } else if event.Kind == TraceFailure && event.Error != nil {
+    text += ": " + event.Error.Error ()
+  }
+  return text
+}
+
+// tracer turns the notifications about named parsers into trace events.
+type tracer struct {
+  callback func (TraceEvent)
+  depth int
+  lock sync.Mutex
+}
+
+// enter and exit only hold the lock while they update the depth. They call
+// the callback afterwards, so that it may run named parsers itself.
+func (tracer *tracer) enter (name string, input ParserInput) {
+  tracer.lock.Lock ()
+  var event = TraceEvent { TraceEnter, name, tracer.depth,
+                           input.Position (), input.Position (), nil }
+  tracer.depth++
+  tracer.lock.Unlock ()
+  tracer.callback (event)
+}
+
+func (tracer *tracer) exit (name string, input ParserInput,
+                            result ParserResult) {
+  tracer.lock.Lock ()
+  tracer.depth--
+  var event = TraceEvent { TraceSuccess, name, tracer.depth,
+                           input.Position (), input.Position (), nil }
+  tracer.lock.Unlock ()
+  if result.Result == nil {
+    event.Kind = TraceFailure
+    event.Error = result.Error
+  } else {
+    event.End = result.RemainingInput.Position ()
+  }
+  tracer.callback (event)
+}
+
+// Trace calls the callback whenever a named parser starts and stops until
+// the returned function is called. Tracing sees the named parsers of all
+// parses in all goroutines, so trace one parse at a time:
+//
+//   var stop = Trace (func (event TraceEvent) { fmt.Println (event) })
+//   var result = Expression (input)
+//   stop ()
+func Trace (callback func (TraceEvent)) func () {
+  return observe (&tracer { callback: callback })
+}
+
+// TraceTo writes the events of the named parsers to the writer, one line per
+// event, until the returned function is called. See Trace.
+func TraceTo (writer io.Writer) func () {
+  return Trace (func (event TraceEvent) {
+    fmt.Fprintln (writer, event)
+  })
+}
@@ -0,0 +1,141 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "strings"
+  "testing"
+  "time"
+)
+
+// tracedSum parses sums of numbers with named parsers.
+var tracedSum = ExpectNumber.Named ("Number").
+  AndThen (ExpectString ("+").AndThen (ExpectNumber.Named ("Number")).
+    Repeated ()).Named ("Sum")
+
+func TestNamedWithoutTracing (t *testing.T) {
+  var result = tracedSum (StringToInput ("1+2"))
+  if result.Result == nil || !result.RemainingInput.AtEnd () {
+    t.Errorf ("Expected the named parser to parse 1+2, got %v!", result.Error)
+  }
+}
+
+func TestTrace (t *testing.T) {
+  var events []TraceEvent
+  var stop = Trace (func (event TraceEvent) {
+    events = append (events, event)
+  })
+  tracedSum (StringToInput ("1+2+x"))
+  stop ()
+  var expected = []string {
+    "Sum enter 1:1",
+    "  Number enter 1:1",
+    "  Number success 1:1-1:2",
+    "  Number enter 1:3",
+    "  Number success 1:3-1:4",
+    "  Number enter 1:5",
+    "  Number failure 1:5: expected number at 1:5, found 'x'",
+    "Sum failure 1:1: expected number at 1:5, found 'x'",
+  }
+  if len (events) != len (expected) {
+    t.Fatalf ("Expected %d events, got %v!", len (expected), events)
+  }
+  for i, event := range events {
+    if event.String () != expected[i] {
+      t.Errorf ("Expected the event %s, got %s!", expected[i], event)
+    }
+  }
+  tracedSum (StringToInput ("1"))
+  if len (events) != len (expected) {
+    t.Errorf ("Expected no events after stopping, got %v!", events)
+  }
+}
+
+func TestTraceAfterPanic (t *testing.T) {
+  var events []TraceEvent
+  var stop = Trace (func (event TraceEvent) {
+    events = append (events, event)
+  })
+  defer stop ()
+  var panicking = Parser (func (input ParserInput) ParserResult {
+    panic ("parser")
+  }).Named ("Panic")
+  func () {
+    defer func () {
+      recover ()
+    } ()
+    ExpectNumber.AndThen (panicking).Named ("Outer") (StringToInput ("1"))
+  } ()
+  tracedSum (StringToInput ("1"))
+  var expected = []string {
+    "Outer enter 1:1",
+    "  Panic enter 1:2",
+    "  Panic failure 1:2",
+    "Outer failure 1:1",
+    "Sum enter 1:1",
+  }
+  for i, text := range expected {
+    if i >= len (events) || events[i].String () != text {
+      t.Fatalf ("Expected the events to start with %v, got %v!",
+        expected, events)
+    }
+  }
+}
+
+func TestTraceCallbackRunsNamedParser (t *testing.T) {
+  var names []string
+  var stop = Trace (func (event TraceEvent) {
+    names = append (names, event.Name)
+    if event.Name == "Sum" && event.Kind == TraceEnter {
+      ExpectNumber.Named ("Number") (StringToInput ("1"))
+    }
+  })
+  var done = make (chan bool)
+  go func () {
+    tracedSum (StringToInput ("2"))
+    done <- true
+  } ()
+  select {
+  case <-done:
+  case <-time.After (5 * time.Second):
+    t.Fatal ("Expected the callback to be able to run named parsers!")
+  }
+  stop ()
+  var expected = "Sum Number Number Number Number Sum"
+  if strings.Join (names, " ") != expected {
+    t.Errorf ("Expected the events of %s, got %v!", expected, names)
+  }
+}
+
+func TestTraceTo (t *testing.T) {
+  var builder strings.Builder
+  var stop = TraceTo (&builder)
+  var stopAgain = TraceTo (&builder)
+  ExpectNumber.Named ("Number") (StringToInput (""))
+  stopAgain ()
+  stop ()
+  stop ()
+  var expected = "Number enter 1:1\nNumber enter 1:1\n" +
+    "Number failure 1:1: expected number at end of input\n" +
+    "Number failure 1:1: expected number at end of input\n"
+  if builder.String () != expected {
+    t.Errorf ("Expected the trace\n%s\ngot\n%s", expected, builder.String ())
+  }
+}
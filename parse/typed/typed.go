@@ -0,0 +1,408 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package typed puts Go generics on top of the parser combinators of the
+// parse package so that the compiler checks the types of the results.
+// Every Parser[T] is a parse.Parser whose successful results are of type T,
+// which is why you can convert between both freely: use Untyped to pass a
+// Parser[T] to the parse package and Lift to get a Parser[T] back.
+package typed
+
+import (
+  "container/list"
+  "fmt"
+  "math/big"
+  "reflect"
+  "unicode"
+
+  "github.com/QAhell/Parser-Gombinators/parse"
+)
+
+// Parser is a parse.Parser that always produces results of type T.
+// Just like in the parse package, a nil result means that parsing failed.
+// Hence a Parser[T] for an interface type T can't produce nil values.
+type Parser[T any] parse.Parser
+
+// Result is the typed version of parse.ParserResult.
+type Result[T any] struct {
+
+  // Value is the result of a successful parse.
+  Value T
+
+  // Ok is true iff parsing succeeded.
+  Ok bool
+
+  // RemainingInput is the rest of the input after the parse.
+  RemainingInput parse.ParserInput
+
+  // Error is the furthest failure that happened during the parse.
+  Error *parse.ParseError
+
+  // Recovered lists the errors that parse.Recover recovered from.
+  Recovered []*parse.ParseError
+}
+
+// Lift declares that the untyped parser produces results of type T.
+// The typed parser panics if the untyped parser produces anything else.
+func Lift[T any] (parser parse.Parser) Parser[T] {
+  return func (input parse.ParserInput) parse.ParserResult {
+    var result = parser (input)
+    if result.Result != nil {
+      if _, isT := result.Result.(T); !isT {
+        panic (fmt.Sprintf ("typed.Lift: expected a result of type %s, " +
+          "got %T", reflect.TypeOf ((*T) (nil)).Elem (), result.Result))
+      }
+    }
+    return result
+  }
+}
+
+// Untyped returns the parser for use with the parse package.
+func (parser Parser[T]) Untyped () parse.Parser {
+  return parse.Parser (parser)
+}
+
+// Parse applies the parser to the input.
+func (parser Parser[T]) Parse (input parse.ParserInput) Result[T] {
+  var result = parser (input)
+  var value, ok = result.Result.(T)
+  return Result[T] {
+    value, ok, result.RemainingInput, result.Error, result.Recovered }
+}
+
+// ParseAll applies the parser to the input and fails unless the parser
+// consumes all of it, see parse.Parser.ParseAll.
+func (parser Parser[T]) ParseAll (input parse.ParserInput) Result[T] {
+  return Parser[T] (parse.Parser (parser).ParseAll).Parse (input)
+}
+
+// Recursive lets the definition of a parser refer to the parser itself.
+func Recursive[T any] (definition func (Parser[T]) Parser[T]) Parser[T] {
+  var parser Parser[T]
+  parser = definition (func (input parse.ParserInput) parse.ParserResult {
+    return parser (input)
+  })
+  return parser
+}
+
+// Succeed doesn't consume any input and always produces the value.
+func Succeed[T any] (value T) Parser[T] {
+  return func (input parse.ParserInput) parse.ParserResult {
+    return parse.ParserResult { Result: value, RemainingInput: input }
+  }
+}
+
+// ExpectCodePoint is the typed version of parse.ExpectCodePoint.
+func ExpectCodePoint (expectedCodePoint rune) Parser[rune] {
+  return Parser[rune] (parse.ExpectCodePoint (expectedCodePoint))
+}
+
+// ExpectString is the typed version of parse.ExpectString.
+func ExpectString (expectedString string) Parser[string] {
+  return Parser[string] (parse.ExpectString (expectedString))
+}
+
+// ExpectIdentifier is the typed version of parse.ExpectIdentifier.
+var ExpectIdentifier = Parser[string] (parse.ExpectIdentifier)
+
+// ExpectNumber is the typed version of parse.ExpectNumber.
+var ExpectNumber = Parser[string] (parse.ExpectNumber)
+
+// ExpectRegexp is the typed version of parse.ExpectRegexp.
+func ExpectRegexp (pattern string) Parser[[]string] {
+  return Parser[[]string] (parse.ExpectRegexp (pattern))
+}
+
+// ExpectStringLiteral is the typed version of parse.ExpectStringLiteral.
+func ExpectStringLiteral (dialect parse.StringDialect) Parser[string] {
+  return Parser[string] (parse.ExpectStringLiteral (dialect))
+}
+
+// ExpectInt64 is the typed version of parse.ExpectInt64.
+var ExpectInt64 = Parser[int64] (parse.ExpectInt64)
+
+// ExpectUint64 is the typed version of parse.ExpectUint64.
+var ExpectUint64 = Parser[uint64] (parse.ExpectUint64)
+
+// ExpectFloat64 is the typed version of parse.ExpectFloat64.
+var ExpectFloat64 = Parser[float64] (parse.ExpectFloat64)
+
+// ExpectBigInt is the typed version of parse.ExpectBigInt.
+var ExpectBigInt = Parser[*big.Int] (parse.ExpectBigInt)
+
+// ExpectUnicodeIdentifier is the typed version of
+// parse.ExpectUnicodeIdentifier.
+var ExpectUnicodeIdentifier = Parser[string] (parse.ExpectUnicodeIdentifier)
+
+// ExpectUnicodeNumber is the typed version of parse.ExpectUnicodeNumber.
+var ExpectUnicodeNumber = Parser[string] (parse.ExpectUnicodeNumber)
+
+// ExpectRuneClass is the typed version of parse.ExpectRuneClass.
+func ExpectRuneClass (tables ...*unicode.RangeTable) Parser[rune] {
+  return Parser[rune] (parse.ExpectRuneClass (tables...))
+}
+
+// MaybeSpacesBefore is the typed version of parse.MaybeSpacesBefore.
+func MaybeSpacesBefore[T any] (parser Parser[T]) Parser[T] {
+  return Parser[T] (parse.MaybeSpacesBefore (parse.Parser (parser)))
+}
+
+// Map applies the mapping to the result of a successful parse.
+func Map[A, B any] (parser Parser[A], mapping func (A) B) Parser[B] {
+  return Parser[B] (parse.Parser (parser).Convert (
+    func (result interface{}) interface{} {
+      return mapping (result.(A))
+    }))
+}
+
+// Pair is the typed version of parse.Pair.
+type Pair[A, B any] struct {
+
+  // First is the first component of the pair.
+  First A
+
+  // Second is the second component of the pair.
+  Second B
+}
+
+// AndThen applies the first parser and then the second one. The result
+// contains the results of both parsers.
+func AndThen[A, B any] (first Parser[A], second Parser[B]) Parser[Pair[A, B]] {
+  return Parser[Pair[A, B]] (parse.Parser (first).
+    AndThen (parse.Parser (second)).
+      Convert (func (result interface{}) interface{} {
+        var pair = result.(parse.Pair)
+        return Pair[A, B] { pair.First.(A), pair.Second.(B) }
+      }))
+}
+
+// First keeps only the first component of the pairs that the parser produces.
+func First[A, B any] (parser Parser[Pair[A, B]]) Parser[A] {
+  return Map (parser, func (pair Pair[A, B]) A { return pair.First })
+}
+
+// Second keeps only the second component of the pairs that the parser
+// produces.
+func Second[A, B any] (parser Parser[Pair[A, B]]) Parser[B] {
+  return Map (parser, func (pair Pair[A, B]) B { return pair.Second })
+}
+
+// Bind uses the result of the parser to construct the parser for the rest
+// of the input.
+func Bind[A, B any] (parser Parser[A], constructor func (A) Parser[B]) Parser[B] {
+  return Parser[B] (parse.Parser (parser).Bind (
+    func (result interface{}) parse.Parser {
+      return parse.Parser (constructor (result.(A)))
+    }))
+}
+
+// OrElse is the typed version of parse.Parser.OrElse.
+func (parser Parser[T]) OrElse (alternativeParser Parser[T]) Parser[T] {
+  return Parser[T] (parse.Parser (parser).
+    OrElse (parse.Parser (alternativeParser)))
+}
+
+// Longest is the typed version of parse.Longest.
+func Longest[T any] (parsers ...Parser[T]) Parser[T] {
+  var untyped = make ([]parse.Parser, len (parsers))
+  for i, parser := range parsers {
+    untyped[i] = parse.Parser (parser)
+  }
+  return Parser[T] (parse.Longest (untyped...))
+}
+
+// Try is the typed version of parse.Try.
+func Try[T any] (parser Parser[T]) Parser[T] {
+  return Parser[T] (parse.Try (parse.Parser (parser)))
+}
+
+// Cut is the typed version of parse.Parser.Cut.
+func (parser Parser[T]) Cut () Parser[T] {
+  return Parser[T] (parse.Parser (parser).Cut ())
+}
+
+// Recover is the typed version of parse.Recover. The recovery turns the
+// ErrorNode of a failure into a T.
+func Recover[T, S any] (parser Parser[T], synchronize Parser[S],
+                        recovery func (parse.ErrorNode) T) Parser[T] {
+  return Parser[T] (parse.Recover (parse.Parser (parser),
+                                   parse.Parser (synchronize)).
+    Convert (func (result interface{}) interface{} {
+      if node, isErrorNode := result.(parse.ErrorNode); isErrorNode {
+        return recovery (node)
+      }
+      return result
+    }))
+}
+
+// Expecting is the typed version of parse.Parser.Expecting.
+func (parser Parser[T]) Expecting (description string) Parser[T] {
+  return Parser[T] (parse.Parser (parser).Expecting (description))
+}
+
+// Named is the typed version of parse.Parser.Named.
+func (parser Parser[T]) Named (name string) Parser[T] {
+  return Parser[T] (parse.Parser (parser).Named (name))
+}
+
+// Seq applies the parsers one after another and collects their results.
+func Seq[T any] (parsers ...Parser[T]) Parser[[]T] {
+  var sequence = parse.Parser (Succeed ([]T {}))
+  for _, parser := range parsers {
+    sequence = sequence.AndThen (parse.Parser (parser)).
+      Convert (func (result interface{}) interface{} {
+        var pair = result.(parse.Pair)
+        return append (pair.First.([]T), pair.Second.(T))
+      })
+  }
+  return Parser[[]T] (sequence)
+}
+
+// listToSlice converts the results of parse.Parser.Repeated.
+func listToSlice[T any] (result interface{}) interface{} {
+  var results = result.(*list.List)
+  var slice = make ([]T, 0, results.Len ())
+  for element := results.Front (); element != nil; element = element.Next () {
+    slice = append (slice, element.Value.(T))
+  }
+  return slice
+}
+
+// Repeated applies the parser zero or more times and collects the results.
+func Repeated[T any] (parser Parser[T]) Parser[[]T] {
+  return Parser[[]T] (parse.Parser (parser).Repeated ().
+    Convert (listToSlice[T]))
+}
+
+// OnceOrMore is like Repeated except that it doesn't allow parsing zero times.
+func OnceOrMore[T any] (parser Parser[T]) Parser[[]T] {
+  return Parser[[]T] (parse.Parser (parser).OnceOrMore ().
+    Convert (listToSlice[T]))
+}
+
+// SepBy is the typed version of parse.Parser.SepBy.
+func SepBy[T, S any] (parser Parser[T], separator Parser[S]) Parser[[]T] {
+  return Parser[[]T] (parse.Parser (parser).SepBy (parse.Parser (separator)).
+    Convert (listToSlice[T]))
+}
+
+// SepBy1 is the typed version of parse.Parser.SepBy1.
+func SepBy1[T, S any] (parser Parser[T], separator Parser[S]) Parser[[]T] {
+  return Parser[[]T] (parse.Parser (parser).SepBy1 (parse.Parser (separator)).
+    Convert (listToSlice[T]))
+}
+
+// EndBy is the typed version of parse.Parser.EndBy.
+func EndBy[T, S any] (parser Parser[T], separator Parser[S]) Parser[[]T] {
+  return Parser[[]T] (parse.Parser (parser).EndBy (parse.Parser (separator)).
+    Convert (listToSlice[T]))
+}
+
+// Between is the typed version of parse.Between.
+func Between[O, C, T any] (open Parser[O], close Parser[C],
+                           parser Parser[T]) Parser[T] {
+  return Parser[T] (parse.Between (parse.Parser (open), parse.Parser (close),
+                                   parse.Parser (parser)))
+}
+
+// ManyTill is the typed version of parse.Parser.ManyTill.
+func ManyTill[T, E any] (parser Parser[T], end Parser[E]) Parser[[]T] {
+  return Parser[[]T] (parse.Parser (parser).ManyTill (parse.Parser (end)).
+    Convert (listToSlice[T]))
+}
+
+// Count is the typed version of parse.Parser.Count.
+func Count[T any] (parser Parser[T], n int) Parser[[]T] {
+  return Parser[[]T] (parse.Parser (parser).Count (n).Convert (listToSlice[T]))
+}
+
+// AtLeast is the typed version of parse.Parser.AtLeast.
+func AtLeast[T any] (parser Parser[T], n int) Parser[[]T] {
+  return Parser[[]T] (parse.Parser (parser).AtLeast (n).
+    Convert (listToSlice[T]))
+}
+
+// AtMost is the typed version of parse.Parser.AtMost.
+func AtMost[T any] (parser Parser[T], n int) Parser[[]T] {
+  return Parser[[]T] (parse.Parser (parser).AtMost (n).Convert (listToSlice[T]))
+}
+
+// LookAhead is the typed version of parse.LookAhead.
+func LookAhead[T any] (parser Parser[T]) Parser[T] {
+  return Parser[T] (parse.LookAhead (parse.Parser (parser)))
+}
+
+// NotFollowedBy is the typed version of parse.NotFollowedBy.
+func NotFollowedBy[T any] (parser Parser[T]) Parser[parse.Nothing] {
+  return Parser[parse.Nothing] (parse.NotFollowedBy (parse.Parser (parser)))
+}
+
+// Chainl1 is the typed version of parse.Parser.Chainl1.
+func Chainl1[T, O any] (parser Parser[T], operator Parser[O],
+                        combine func (O, T, T) T) Parser[T] {
+  return Parser[T] (parse.Parser (parser).Chainl1 (parse.Parser (operator),
+    func (operator interface{}, left interface{},
+          right interface{}) interface{} {
+      return combine (operator.(O), left.(T), right.(T))
+    }))
+}
+
+// Chainr1 is the typed version of parse.Parser.Chainr1.
+func Chainr1[T, O any] (parser Parser[T], operator Parser[O],
+                        combine func (O, T, T) T) Parser[T] {
+  return Parser[T] (parse.Parser (parser).Chainr1 (parse.Parser (operator),
+    func (operator interface{}, left interface{},
+          right interface{}) interface{} {
+      return combine (operator.(O), left.(T), right.(T))
+    }))
+}
+
+// RepeatAndFoldLeft is the typed version of parse.Parser.RepeatAndFoldLeft.
+func RepeatAndFoldLeft[T, A any] (parser Parser[T], accumulator A,
+                                  combine func (A, T) A) Parser[A] {
+  return Parser[A] (parse.Parser (parser).RepeatAndFoldLeft (accumulator,
+    func (accumulated interface{}, result interface{}) interface{} {
+      return combine (accumulated.(A), result.(T))
+    }))
+}
+
+// Maybe is the result of Optional. If Valid is false then the parser
+// didn't parse anything and Value is the zero value.
+type Maybe[T any] struct {
+
+  // Value is the result of the parser if Valid is true.
+  Value T
+
+  // Valid is true iff the parser parsed something.
+  Valid bool
+}
+
+// Optional applies the parser zero or one times to the input.
+func Optional[T any] (parser Parser[T]) Parser[Maybe[T]] {
+  var valid = Map (parser, func (value T) Maybe[T] {
+    return Maybe[T] { value, true }
+  })
+  return Parser[Maybe[T]] (valid.Untyped ().Optional ().
+    Convert (func (result interface{}) interface{} {
+      if maybe, isMaybe := result.(Maybe[T]); isMaybe {
+        return maybe
+      }
+      return Maybe[T] {}
+    }))
+}
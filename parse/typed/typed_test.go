@@ -0,0 +1,138 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package typed
+
+import (
+  "strconv"
+  "testing"
+
+  "github.com/QAhell/Parser-Gombinators/parse"
+)
+
+func atoi (text string) int {
+  var number, _ = strconv.Atoi (text)
+  return number
+}
+
+// sum parses Sum := Number ("+" Number)* | "(" Sum ")"
+var sum = Recursive (func (sum Parser[int]) Parser[int] {
+  var number = Map (ExpectNumber, atoi).OrElse (
+    Second (AndThen (ExpectString ("("),
+                     First (AndThen (sum, ExpectString (")"))))))
+  return Bind (number, func (first int) Parser[int] {
+    return RepeatAndFoldLeft (Second (AndThen (ExpectString ("+"), number)),
+      first, func (lhs int, rhs int) int { return lhs + rhs })
+  })
+})
+
+func TestRecursiveSum (t *testing.T) {
+  var result = sum.Parse (parse.StringToInput ("1+(2+3)+4"))
+  if !result.Ok || result.Value != 10 || !result.RemainingInput.AtEnd () {
+    t.Errorf ("Expected the parser to compute 10, got %v!", result)
+  }
+  result = sum.Parse (parse.StringToInput ("(1+2"))
+  if result.Ok {
+    t.Errorf ("Expected the parser to fail!")
+  }
+  if result.Error.Error () != "expected '+' or ')' at end of input" {
+    t.Errorf ("Unexpected error: %s", result.Error)
+  }
+  if sum.ParseAll (parse.StringToInput ("1+2)")).Ok ||
+     !sum.ParseAll (parse.StringToInput ("1+2")).Ok {
+    t.Errorf ("Expected ParseAll to fail unless it gets to the end!")
+  }
+}
+
+func TestSeqAndRepeated (t *testing.T) {
+  var parser = AndThen (
+    Seq (ExpectCodePoint ('a'), ExpectCodePoint ('b')),
+    Repeated (ExpectCodePoint ('c')))
+  var result = parser.Parse (parse.StringToInput ("abccd"))
+  if !result.Ok || string (result.Value.First) != "ab" ||
+     string (result.Value.Second) != "cc" {
+    t.Errorf ("Expected the parser to parse ab and cc, got %v!", result)
+  }
+  if OnceOrMore (ExpectCodePoint ('c')).Parse (
+       parse.StringToInput ("d")).Ok {
+    t.Errorf ("Expected OnceOrMore to fail!")
+  }
+}
+
+func TestSepByAndChains (t *testing.T) {
+  var arguments = Between (ExpectString ("("), ExpectString (")"),
+    SepBy (ExpectInt64, ExpectString (",")))
+  var result = arguments.Parse (parse.StringToInput ("(1,2,3)"))
+  if !result.Ok || len (result.Value) != 3 || result.Value[2] != 3 {
+    t.Errorf ("Expected the arguments 1, 2 and 3, got %v!", result)
+  }
+  var power = Chainr1 (ExpectInt64, ExpectString ("^"),
+    func (operator string, left int64, right int64) int64 {
+      var value = int64 (1)
+      for i := int64 (0); i < right; i++ {
+        value *= left
+      }
+      return value
+    })
+  if value := power.Parse (parse.StringToInput ("2^3^2")).Value;
+     value != 512 {
+    t.Errorf ("Expected 2^3^2 to be 512, got %d!", value)
+  }
+}
+
+func TestOptional (t *testing.T) {
+  var parser = AndThen (ExpectIdentifier,
+    Optional (Second (AndThen (ExpectString ("="), ExpectNumber))))
+  var result = parser.Parse (parse.StringToInput ("x=42"))
+  if !result.Ok || !result.Value.Second.Valid ||
+     result.Value.Second.Value != "42" {
+    t.Errorf ("Expected the parser to parse the number, got %v!", result)
+  }
+  result = parser.Parse (parse.StringToInput ("x;"))
+  if !result.Ok || result.Value.Second.Valid ||
+     result.RemainingInput.CurrentCodePoint () != ';' {
+    t.Errorf ("Expected the parser to parse no number, got %v!", result)
+  }
+}
+
+func TestLift (t *testing.T) {
+  var identifier = Lift[string] (parse.MaybeSpacesBefore (
+    parse.ExpectIdentifier))
+  var result = MaybeSpacesBefore (identifier).Parse (
+    parse.StringToInput ("  ning"))
+  if !result.Ok || result.Value != "ning" {
+    t.Errorf ("Expected the parser to parse ning, got %v!", result)
+  }
+  defer func () {
+    if recover () == nil {
+      t.Errorf ("Expected Lift to panic on results of the wrong type!")
+    }
+  } ()
+  Lift[int] (parse.ExpectIdentifier).Parse (parse.StringToInput ("ning"))
+}
+
+func TestRecover (t *testing.T) {
+  var numbers = Repeated (Recover (First (AndThen (sum, ExpectString (";"))),
+    ExpectString (";"), func (parse.ErrorNode) int { return -1 }))
+  var result = numbers.Parse (parse.StringToInput ("1+2;x;3;"))
+  if !result.Ok || len (result.Value) != 3 || result.Value[1] != -1 ||
+     len (result.Recovered) != 1 {
+    t.Errorf ("Expected the parser to recover from x, got %v!", result)
+  }
+}
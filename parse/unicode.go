@@ -0,0 +1,106 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "sort"
+  "unicode"
+)
+
+// isUnicodeIdentifierStartChar implements ID_Start of Unicode Standard Annex
+// #31, along with the underscore.
+func isUnicodeIdentifierStartChar (codePoint rune) bool {
+  return codePoint == '_' ||
+    unicode.In (codePoint, unicode.L, unicode.Nl, unicode.Other_ID_Start) &&
+      !unicode.In (codePoint, unicode.Pattern_Syntax,
+                   unicode.Pattern_White_Space)
+}
+
+// isUnicodeIdentifierChar implements ID_Continue of Unicode Standard Annex
+// #31.
+func isUnicodeIdentifierChar (codePoint rune) bool {
+  return isUnicodeIdentifierStartChar (codePoint) ||
+    unicode.In (codePoint, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc,
+                unicode.Other_ID_Continue) &&
+      !unicode.In (codePoint, unicode.Pattern_Syntax,
+                   unicode.Pattern_White_Space)
+}
+
+// ExpectUnicodeIdentifier parses an identifier as defined by Unicode Standard
+// Annex #31, e.g. 熊猫 or élan, which may also start with an underscore.
+// Unlike ExpectIdentifier it isn't restricted to ASCII.
+var ExpectUnicodeIdentifier Parser =
+  ExpectSeveral (isUnicodeIdentifierStartChar, isUnicodeIdentifierChar).
+    Expecting ("identifier")
+
+// ExpectUnicodeSpaces parses all white space as defined by unicode.IsSpace,
+// including non-breaking spaces. Unlike ExpectSpaces it isn't restricted to
+// ASCII.
+var ExpectUnicodeSpaces Parser =
+  ExpectSeveral (unicode.IsSpace, unicode.IsSpace).Optional ()
+
+// ExpectUnicodeNumber parses one or more decimal digits of any script, e.g.
+// ٤٢, and the result will be a string. Unlike ExpectNumber it isn't
+// restricted to ASCII.
+var ExpectUnicodeNumber Parser =
+  ExpectSeveral (unicode.IsDigit, unicode.IsDigit).Expecting ("number")
+
+// ExpectRuneClass expects exactly one code point from one of the tables of
+// the package unicode, e.g. unicode.Han or unicode.Lu. The code point is the
+// result.
+func ExpectRuneClass (tables ...*unicode.RangeTable) Parser {
+  var description = runeClassDescription (tables)
+  return func (input ParserInput) ParserResult {
+    if !input.AtEnd () && unicode.IsOneOf (tables, input.CurrentCodePoint ()) {
+      return ParserResult {
+        Result: input.CurrentCodePoint (),
+        RemainingInput: input.RemainingInput () }
+    }
+    return Failure (input, description)
+  }
+}
+
+// runeClassDescription names the tables for error messages, e.g.
+// "code point in Han or Latin".
+func runeClassDescription (tables []*unicode.RangeTable) string {
+  var names []string
+  for _, table := range tables {
+    names = append (names, rangeTableName (table))
+  }
+  return "code point in " + joinAlternatives (names)
+}
+
+// rangeTableName finds the name of a table of the package unicode.
+func rangeTableName (table *unicode.RangeTable) string {
+  for _, tables := range []map[string]*unicode.RangeTable {
+      unicode.Categories, unicode.Scripts, unicode.Properties } {
+    var names []string
+    for name, candidate := range tables {
+      if candidate == table {
+        names = append (names, name)
+      }
+    }
+    if len (names) > 0 {
+      sort.Strings (names)
+      return names[0]
+    }
+  }
+  return "custom class"
+}
@@ -0,0 +1,96 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "testing"
+  "unicode"
+)
+
+func TestExpectUnicodeIdentifier (t *testing.T) {
+  var tests = []struct { input, expected string } {
+    { "熊猫 ist", "熊猫" },
+    { "élan-vital", "élan" },
+    { "_x1", "_x1" },
+    { "été", "été" },
+    { "x٤٢", "x٤٢" },
+  }
+  for _, test := range tests {
+    var result = ExpectUnicodeIdentifier (StringToInput (test.input))
+    if result.Result != test.expected {
+      t.Errorf ("Expected the identifier %s in %s, got %v!",
+        test.expected, test.input, result.Result)
+    }
+  }
+  for _, input := range []string { "1a", "\u0301a", "\u00b7a", "" } {
+    if ExpectUnicodeIdentifier (StringToInput (input)).Result != nil {
+      t.Errorf ("Expected %s not to start an identifier!", input)
+    }
+  }
+  if ExpectIdentifier (StringToInput ("熊猫")).Result != nil {
+    t.Errorf ("Expected ExpectIdentifier to stay ASCII-only!")
+  }
+}
+
+func TestExpectUnicodeSpaces (t *testing.T) {
+  var result = ExpectUnicodeSpaces (StringToInput ("\u00a0\u2003 \tx"))
+  if result.RemainingInput.CurrentCodePoint () != 'x' {
+    t.Errorf ("Expected the spaces to be skipped, got %c!",
+      result.RemainingInput.CurrentCodePoint ())
+  }
+  if ExpectUnicodeSpaces (StringToInput ("x")).Result != (Nothing {}) {
+    t.Errorf ("Expected Nothing if there are no spaces!")
+  }
+  result = ExpectSpaces (StringToInput ("\u00a0x"))
+  if offsetOf (result.RemainingInput) != 0 {
+    t.Errorf ("Expected ExpectSpaces to stay ASCII-only!")
+  }
+}
+
+func TestExpectUnicodeNumber (t *testing.T) {
+  if result := ExpectUnicodeNumber (StringToInput ("٤٢x")); result.Result != "٤٢" {
+    t.Errorf ("Expected ٤٢, got %v!", result.Result)
+  }
+  var result = ExpectUnicodeNumber (StringToInput ("x"))
+  if result.Error == nil ||
+     result.Error.Error () != "expected number at 1:1, found 'x'" {
+    t.Errorf ("Expected a number to be missing, got %v!", result.Error)
+  }
+}
+
+func TestExpectRuneClass (t *testing.T) {
+  var han = ExpectRuneClass (unicode.Han)
+  if han (StringToInput ("熊")).Result != '熊' {
+    t.Errorf ("Expected 熊 to be a Han code point!")
+  }
+  var result = ExpectRuneClass (unicode.Han, unicode.Lu) (StringToInput ("x"))
+  if result.Error == nil || result.Error.Error () !=
+     "expected code point in Han or Lu at 1:1, found 'x'" {
+    t.Errorf ("Expected the names of the tables in the error, got %v!",
+      result.Error)
+  }
+  var custom = &unicode.RangeTable {
+    R16: []unicode.Range16 { { 'a', 'c', 1 } } }
+  result = ExpectRuneClass (custom) (StringToInput (""))
+  if result.Error == nil || result.Error.Error () !=
+     "expected code point in custom class at end of input" {
+    t.Errorf ("Expected a custom class to be missing, got %v!", result.Error)
+  }
+}
@@ -0,0 +1,137 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "unicode/utf8"
+)
+
+// UTF8StringInput is a ParserInput that decodes the code points of a UTF-8
+// encoded string one at a time. Unlike RuneArrayInput it doesn't convert
+// the whole string into runes, which takes up to four times the memory.
+// Invalid UTF-8 decodes to utf8.RuneError, one byte at a time.
+type UTF8StringInput struct {
+
+  // Text is the whole UTF-8 encoded input text.
+  Text string
+
+  // position is where the current code point starts in the Text
+  position Position
+}
+
+// UTF8StringToInput converts a string to a UTF8StringInput so you can use
+// parsers on it.
+func UTF8StringToInput (text string) ParserInput {
+  return UTF8StringInput { text, startPosition }
+}
+
+// CurrentCodePoint decodes the code point at the current byte offset.
+func (input UTF8StringInput) CurrentCodePoint () rune {
+  if input.AtEnd () {
+    return EndOfInput
+  }
+  var codePoint, _ = utf8.DecodeRuneInString (
+    input.Text[input.position.ByteOffset:])
+  return codePoint
+}
+
+// RemainingInput is the text after the current code point.
+func (input UTF8StringInput) RemainingInput () ParserInput {
+  if input.AtEnd () {
+    return input
+  }
+  var codePoint, width = utf8.DecodeRuneInString (
+    input.Text[input.position.ByteOffset:])
+  return UTF8StringInput {
+    input.Text, input.position.advance (codePoint, width) }
+}
+
+// Position is the position of the current code point.
+func (input UTF8StringInput) Position () Position {
+  return input.position
+}
+
+// AtEnd is true iff the whole text has been decoded.
+func (input UTF8StringInput) AtEnd () bool {
+  return input.position.ByteOffset >= len (input.Text)
+}
+
+// Substring returns the text from this input up to the end input, which
+// must be a later part of the same UTF8StringInput. It doesn't copy the text.
+func (input UTF8StringInput) Substring (end ParserInput) string {
+  return input.Text[input.position.ByteOffset:end.Position ().ByteOffset]
+}
+
+// BytesInput is like UTF8StringInput for UTF-8 encoded bytes.
+type BytesInput struct {
+
+  // Bytes are the whole UTF-8 encoded input. Please keep them unchanged while
+  // parsers are working on them.
+  Bytes []byte
+
+  // position is where the current code point starts in the Bytes
+  position Position
+}
+
+// BytesToInput converts bytes to a BytesInput so you can use parsers on them.
+// Don't modify the bytes while parsing.
+func BytesToInput (bytes []byte) ParserInput {
+  return BytesInput { bytes, startPosition }
+}
+
+// CurrentCodePoint decodes the code point at the current byte offset.
+func (input BytesInput) CurrentCodePoint () rune {
+  if input.AtEnd () {
+    return EndOfInput
+  }
+  var codePoint, _ = utf8.DecodeRune (input.Bytes[input.position.ByteOffset:])
+  return codePoint
+}
+
+// RemainingInput is the text after the current code point.
+func (input BytesInput) RemainingInput () ParserInput {
+  if input.AtEnd () {
+    return input
+  }
+  var codePoint, width = utf8.DecodeRune (
+    input.Bytes[input.position.ByteOffset:])
+  return BytesInput { input.Bytes, input.position.advance (codePoint, width) }
+}
+
+// Position is the position of the current code point.
+func (input BytesInput) Position () Position {
+  return input.position
+}
+
+// AtEnd is true iff all bytes have been decoded.
+func (input BytesInput) AtEnd () bool {
+  return input.position.ByteOffset >= len (input.Bytes)
+}
+
+// Slice returns the bytes from this input up to the end input, which must be
+// a later part of the same BytesInput. It doesn't copy the bytes.
+func (input BytesInput) Slice (end ParserInput) []byte {
+  return input.Bytes[input.position.ByteOffset:end.Position ().ByteOffset]
+}
+
+// Substring is like Slice but returns a copy of the bytes as a string.
+func (input BytesInput) Substring (end ParserInput) string {
+  return string (input.Slice (end))
+}
@@ -0,0 +1,133 @@
+/*
+    © 2018 Armin Heller
+
+    This file is part of Parser-Gombinators.
+
+    Parser-Gombinators is free software: you can redistribute it and/or modify
+    it under the terms of the GNU General Public License as published by
+    the Free Software Foundation, either version 3 of the License, or
+    (at your option) any later version.
+
+    Parser-Gombinators is distributed in the hope that it will be useful,
+    but WITHOUT ANY WARRANTY; without even the implied warranty of
+    MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+    GNU General Public License for more details.
+
+    You should have received a copy of the GNU General Public License
+    along with Parser-Gombinators. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestUTF8Inputs (t *testing.T) {
+  var text = "añb\n€x"
+  for _, input := range []ParserInput {
+      UTF8StringToInput (text), BytesToInput ([]byte (text)) } {
+    var expected = StringToInput (text)
+    for ; !input.AtEnd (); input = input.RemainingInput () {
+      if input.CurrentCodePoint () != expected.CurrentCodePoint () ||
+         input.Position () != expected.Position () {
+        t.Errorf ("Expected %c at %v, got %c at %v!",
+          expected.CurrentCodePoint (), expected.Position (),
+          input.CurrentCodePoint (), input.Position ())
+      }
+      expected = expected.RemainingInput ()
+    }
+    if !expected.AtEnd () || input.Position () != expected.Position () {
+      t.Errorf ("Expected the input to end together with the runes!")
+    }
+  }
+}
+
+func TestInvalidUTF8 (t *testing.T) {
+  var input = UTF8StringToInput ("a\xffb")
+  var result = ExpectCodePoint ('a').AndThen (ExpectCodePoint ('�')).
+    AndThen (ExpectCodePoint ('b')) (input)
+  if result.Result == nil || !result.RemainingInput.AtEnd () {
+    t.Errorf ("Expected invalid UTF-8 to decode to the replacement character!")
+  }
+}
+
+func TestSubstring (t *testing.T) {
+  var text = "key = välue;"
+  var parser = ExpectIdentifier.AndThen (MaybeSpacesBefore (ExpectString ("=")))
+  for _, input := range []ParserInput {
+      UTF8StringToInput (text), BytesToInput ([]byte (text)) } {
+    var value = parser (input).RemainingInput.RemainingInput ()
+    var end = ExpectNotCodePoint ([]rune { ';' }).OnceOrMore () (value)
+    var substring = value.(interface {
+      Substring (ParserInput) string }).Substring (end.RemainingInput)
+    if substring != "välue" {
+      t.Errorf ("Expected the substring välue, got %s!", substring)
+    }
+    var rest = ExpectNotCodePoint ([]rune {}).Repeated () (value)
+    if value.(interface {
+         Substring (ParserInput) string }).Substring (
+           rest.RemainingInput) != "välue;" {
+      t.Errorf ("Expected the substring up to the end of the input!")
+    }
+  }
+}
+
+// logLines is an ASCII-heavy text for the benchmarks.
+var logLines = strings.Repeat (
+  "2018-01-01 12:00:00 INFO request served in 42 ms\n", 1000)
+
+// logParser parses the logLines into words, numbers and other code points.
+var logParser = ExpectIdentifier.OrElse (ExpectNumber).
+  OrElse (ExpectNotCodePoint ([]rune {})).Repeated ()
+
+func benchmarkInput (b *testing.B, toInput func (string) ParserInput) {
+  b.ReportAllocs ()
+  for i := 0; i < b.N; i++ {
+    var result = logParser (toInput (logLines))
+    if result.Result == nil || !result.RemainingInput.AtEnd () {
+      b.Fatalf ("Expected the parser to parse the whole input!")
+    }
+  }
+}
+
+func BenchmarkRuneArrayInput (b *testing.B) {
+  benchmarkInput (b, StringToInput)
+}
+
+func BenchmarkUTF8StringInput (b *testing.B) {
+  benchmarkInput (b, UTF8StringToInput)
+}
+
+func BenchmarkBytesInput (b *testing.B) {
+  var bytes = []byte (logLines)
+  benchmarkInput (b, func (string) ParserInput { return BytesToInput (bytes) })
+}
+
+// keptInput keeps the inputs of the size benchmarks alive.
+var keptInput ParserInput
+
+// benchmarkInputSize measures the memory that an input of the logLines keeps
+// by itself, without the inputs that the parsers create on the way.
+func benchmarkInputSize (b *testing.B, toInput func (string) ParserInput) {
+  b.ReportAllocs ()
+  for i := 0; i < b.N; i++ {
+    keptInput = toInput (logLines)
+  }
+}
+
+func BenchmarkRuneArrayInputSize (b *testing.B) {
+  benchmarkInputSize (b, StringToInput)
+}
+
+func BenchmarkUTF8StringInputSize (b *testing.B) {
+  benchmarkInputSize (b, UTF8StringToInput)
+}
+
+func BenchmarkBytesInputSize (b *testing.B) {
+  var bytes = []byte (logLines)
+  benchmarkInputSize (b, func (string) ParserInput {
+    return BytesToInput (bytes)
+  })
+}
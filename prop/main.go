@@ -23,7 +23,8 @@ import (
   "os"
   "fmt"
   . "github.com/QAhell/Parser-Gombinators/parse"
-  "strings"
+  "github.com/QAhell/Parser-Gombinators/parse/typed"
+  "strconv"
   "encoding/json"
   "io/ioutil"
 )
@@ -39,7 +40,7 @@ import (
   simplified.
 
   Identifier   := [a-zA-Z_][a-zA-Z0-9_]* except for keywords
-  String       := ["]([^"]|[\\]["])*["]
+  String       := a Go string literal like "a\tb\"c"
   Bool         := TRUE | FALSE
   Atom         := Identifier
                 | Bool
@@ -62,26 +63,21 @@ func main () {
   }
   var env = readEnvironment ()
   var input = StringToInput (os.Args[len (os.Args) - 1])
-  var parserResult = ParseOr (input)
+  var parserResult = Parser (ParseOr).AndThen (ExpectSpaces).First ().
+    ParseAll (input)
   var term, isTerm = parserResult.Result.(Term)
   if isTerm {
-    presentResult (term, env, parserResult)
+    presentResult (term, env)
   } else {
-    fmt.Printf ("Can't parse the input!\n")
+    fmt.Printf ("Can't parse the input: %s\n", parserResult.Error)
   }
 }
 
-/* presentResult shows to the user the parsed term, the simplified term
-  and remaining input from the formula text */
-func presentResult (term Term, env map[string] Value,
-                    parserResult ParserResult) {
+/* presentResult shows to the user the parsed term and the simplified
+  term */
+func presentResult (term Term, env map[string] Value) {
   fmt.Printf ("parsed expression = %s\n", term.String ())
   fmt.Printf ("simplified result = %s\n", term.Simplify (env).String ())
-  if nil != parserResult.RemainingInput {
-    var inp = parserResult.RemainingInput.(RuneArrayInput)
-    var rest = inp.Text[inp.CurrentPosition:]
-    fmt.Printf ("There's some remaining input: %s\n", string (rest))
-  }
 }
 
 /* readEnvironment reads the contents of the JSON-file supplied by the
@@ -133,10 +129,10 @@ type StringValue struct { Value string }
 /* BoolValue just wraps the bool type */
 type BoolValue struct { Value bool }
 
-/* String wraps the contents of the string with quotes and escapes quotes
-  inside the string */
+/* String wraps the contents of the string with quotes and escapes quotes,
+  backslashes and control characters inside the string like Go does */
 func (str *StringValue) String () string {
-  return "\"" + strings.NewReplacer ("\"", "\\\"").Replace (str.Value) + "\""
+  return strconv.Quote (str.Value)
 }
 
 /* String converts true to "TRUE" and false to "FALSE" */
@@ -404,16 +400,9 @@ func ParseBool (input ParserInput) ParserResult {
     }) (input)
 }
 
-/* ParseString parses a string literal */
+/* ParseString parses a string literal with the escape sequences of Go */
 func ParseString (input ParserInput) ParserResult {
-  return ExpectCodePoint ('"').AndThen (
-            (ExpectCodePoint ('\\').AndThen (ExpectCodePoint ('"')).Second ().
-             OrElse (ExpectNotCodePoint ([]rune { '"' }))).
-              RepeatAndFoldLeft ("",
-                func (acc interface{}, char interface{}) interface{} {
-                  return acc.(string) + string (char.(rune))
-                })).Second ().
-            AndThen (ExpectCodePoint ('"')).First () (input)
+  return ExpectStringLiteral (GoString) (input)
 }
 
 /* ParseValue parses a string literal or a boolean */
@@ -451,7 +440,7 @@ func expectIdent (text string) Parser {
       if (result.Result == text) {
         return result
       } else {
-        return ParserResult { nil, input }
+        return Failure (input, "'" + text + "'")
       }
     })
 }
@@ -466,16 +455,15 @@ func ParseAtom (input ParserInput) ParserResult {
 
 /* ParseEqn parses equations and atoms */
 func ParseEqn (input ParserInput) ParserResult {
-  return Parser (ParseAtom).AndThen (
-    expect ("=").AndThen (ParseAtom).Second ().Optional ()).
-      Convert (func (arg interface{}) interface{} {
-          var pair = arg.(Pair)
-          var lhs = pair.First.(Term)
-          if pair.Second == (Nothing{}) {
-            return lhs
-          }
-          return &Equation { lhs, pair.Second.(Term) }
-        }) (input)
+  return typed.Map (typed.AndThen (typed.Lift[Term] (ParseAtom),
+      typed.Optional (typed.Second (typed.AndThen (
+        typed.Lift[string] (expect ("=")), typed.Lift[Term] (ParseAtom))))),
+    func (eqn typed.Pair[Term, typed.Maybe[Term]]) Term {
+      if !eqn.Second.Valid {
+        return eqn.First
+      }
+      return &Equation { eqn.First, eqn.Second.Value }
+    }).Untyped () (input)
 }
 
 /* ParseNot subsumes ParseEqn and parses negations and drops double negations.
@@ -483,46 +471,44 @@ func ParseEqn (input ParserInput) ParserResult {
   logic. */
 func ParseNot (input ParserInput) ParserResult {
   // see the comment on expectIdent for why not expect("NOT")
-  return expectIdent ("NOT").RepeatAndFoldLeft (false,
-      func (negate interface{}, _ interface{}) interface{} { return !negate.(bool) }).
-    AndThen (ParseEqn).Convert (func (arg interface{}) interface{} {
-        var pair, _ = arg.(Pair)
-        var negate = pair.First.(bool)
-        var term = pair.Second.(Term)
-        if negate {
-          return &Not { term }
-        } else {
-          return term
-        }
-      }) (input)
+  var negations = typed.Repeated (typed.Lift[string] (expectIdent ("NOT")))
+  return typed.Map (typed.AndThen (negations, typed.Lift[Term] (ParseEqn)),
+    func (not typed.Pair[[]string, Term]) Term {
+      if len (not.First) % 2 == 1 {
+        return &Not { not.Second }
+      }
+      return not.Second
+    }).Untyped () (input)
 }
 
 /* ParseAnd subsumes ParseNot and parses conjunctions */
 func ParseAnd (input ParserInput) ParserResult {
   // see the comment on expectIdent for why not expect("AND")
-  return Parser (ParseNot).AndThen (
-      expectIdent ("AND").AndThen (ParseAnd).Second ().Optional ()).
-        Convert (func (arg interface{}) interface{} {
-                  var pair, _ = arg.(Pair)
-                  if pair.Second == (Nothing{}) {
-                    return pair.First.(Term)
-                  }
-                  return &And { pair.First.(Term), pair.Second.(Term) }
-                }) (input)
+  return typed.Map (typed.AndThen (typed.Lift[Term] (ParseNot),
+      typed.Optional (typed.Second (typed.AndThen (
+        typed.Lift[string] (expectIdent ("AND")),
+        typed.Lift[Term] (ParseAnd))))),
+    func (and typed.Pair[Term, typed.Maybe[Term]]) Term {
+      if !and.Second.Valid {
+        return and.First
+      }
+      return &And { and.First, and.Second.Value }
+    }).Untyped () (input)
 }
 
 /* ParseOr subsumes ParseAnd and parses disjunctions */
 func ParseOr (input ParserInput) ParserResult {
   // see the comment on expectIdent for why not expect("OR")
-  return Parser (ParseAnd).AndThen (
-      expectIdent ("OR").AndThen (ParseOr).Second ().Optional ()).
-        Convert (func (arg interface{}) interface{} {
-                  var pair, _ = arg.(Pair)
-                  if pair.Second == (Nothing{}) {
-                    return pair.First.(Term)
-                  }
-                  return &Or { pair.First.(Term), pair.Second.(Term) }
-                }) (input)
+  return typed.Map (typed.AndThen (typed.Lift[Term] (ParseAnd),
+      typed.Optional (typed.Second (typed.AndThen (
+        typed.Lift[string] (expectIdent ("OR")),
+        typed.Lift[Term] (ParseOr))))),
+    func (or typed.Pair[Term, typed.Maybe[Term]]) Term {
+      if !or.Second.Valid {
+        return or.First
+      }
+      return &Or { or.First, or.Second.Value }
+    }).Untyped () (input)
 }
 
 /* expect trys to find a certain text at the beginning of the input */
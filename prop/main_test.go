@@ -135,3 +135,22 @@ func TestParser (t *testing.T) {
       "term %s.", text, term)
   }
 }
+
+func TestStringRoundTrip (t *testing.T) {
+  for _, text := range []string { "", "a\"b", "C:\\dir\\", "tab\there\n",
+                                   "熊猫" } {
+    var value = &StringValue { text }
+    var result = ParseValue (StringToInput (value.String ()))
+    var term, isTerm = result.Result.(*ValueTerm)
+    if !isTerm || !value.Equals (term.Value) {
+      t.Errorf ("Expected %s to be parsed back into %q, got %v!",
+        value, text, result.Result)
+    }
+  }
+  var result = ParseString (StringToInput ("\"a\\qb\""))
+  if result.Error == nil || result.Error.Error () !=
+       "expected escape sequence at 1:3, found '\\\\q'" {
+    t.Errorf ("Expected the invalid escape to be an error, got %v!",
+      result.Error)
+  }
+}